@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lukas-reining/lox/coverage"
+	"os"
+)
+
+func cover(args []string) int {
+	flags := flag.NewFlagSet("cover", flag.ContinueOnError)
+	htmlOut := flags.String("html", "", "write an annotated HTML page to `file` instead of printing the source")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		fmt.Println("Usage: glox cover [--html=file] [coverage.json]")
+		return 64
+	}
+
+	report, err := coverage.ReadReport(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	source, err := report.ReadSource()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if *htmlOut == "" {
+		if err := report.WriteText(os.Stdout, source); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	file, err := os.Create(*htmlOut)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer file.Close()
+
+	if err := report.WriteHtml(file, source); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,220 @@
+package coverage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/parser/statements"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+type StatementCoverage struct {
+	Line int `json:"line"`
+	Hits int `json:"hits"`
+}
+
+type BranchCoverage struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+	Kind   string `json:"kind"`
+	// How often each outcome of the branch was taken.
+	Outcomes map[interpeter.BranchKind]int `json:"outcomes"`
+}
+
+type Report struct {
+	// The absolute path of the script.
+	File string `json:"file"`
+	// The SHA-256 of the script's source, so that a changed script is not annotated.
+	SourceHash string              `json:"sourceHash"`
+	Statements []StatementCoverage `json:"statements"`
+	Branches   []BranchCoverage    `json:"branches"`
+}
+
+// The Collector is a hook that counts how often each statement ran and which outcomes each
+// branch took. Statements and branches that never ran are known from the program.
+type Collector struct {
+	interpeter.BaseHook
+
+	mutex      sync.Mutex
+	statements map[statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]]*StatementCoverage
+	branches   map[any]*BranchCoverage
+	// Statements and branches in the order they were first seen, which keeps reports stable.
+	statementOrder []*StatementCoverage
+	branchOrder    []*BranchCoverage
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		statements: map[statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]]*StatementCoverage{},
+		branches:   map[any]*BranchCoverage{},
+	}
+}
+
+func (c *Collector) BeforeProgram(interpreter *interpeter.Interpreter, program *interpeter.Program) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Methods and lambdas are functions that are never executed as statements themselves.
+	notExecuted := map[any]bool{}
+
+	interpeter.WalkSyntaxTree(program.Statements(), func(node any) {
+		switch n := node.(type) {
+		case *statements.Class[interpeter.LoxValue, interpeter.RuntimeError]:
+			for _, methods := range [][]statements.Function[interpeter.LoxValue, interpeter.RuntimeError]{n.Methods, n.StaticMethods, n.Getters, n.Setters} {
+				for index := range methods {
+					notExecuted[&methods[index]] = true
+				}
+			}
+		case *statements.Trait[interpeter.LoxValue, interpeter.RuntimeError]:
+			for index := range n.Methods {
+				notExecuted[&n.Methods[index]] = true
+			}
+		case *expressions.Lambda[interpeter.LoxValue, interpeter.RuntimeError]:
+			notExecuted[n.Function] = true
+		}
+
+		if statement, ok := node.(statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]); ok && !notExecuted[node] {
+			c.statement(statement)
+		}
+		c.branchPoint(node)
+	})
+}
+
+func (c *Collector) BeforeStatement(interpreter *interpeter.Interpreter, statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) interpeter.RuntimeError {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.statement(statement).Hits++
+	return nil
+}
+
+func (c *Collector) Branch(interpreter *interpeter.Interpreter, node any, branch interpeter.BranchKind) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if point := c.branchPoint(node); point != nil {
+		point.Outcomes[branch]++
+	}
+}
+
+func (c *Collector) statement(statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) *StatementCoverage {
+	coverage, ok := c.statements[statement]
+	if !ok {
+		coverage = &StatementCoverage{Line: statement.Line()}
+		c.statements[statement] = coverage
+		c.statementOrder = append(c.statementOrder, coverage)
+	}
+	return coverage
+}
+
+// Returns the branch point for the node, or nil if the node doesn't branch.
+func (c *Collector) branchPoint(node any) *BranchCoverage {
+	if point, ok := c.branches[node]; ok {
+		return point
+	}
+
+	var coverage BranchCoverage
+	switch n := node.(type) {
+	case *statements.If[interpeter.LoxValue, interpeter.RuntimeError]:
+		coverage = BranchCoverage{Line: n.SourceLine, Kind: "if", Outcomes: outcomes(interpeter.THEN_BRANCH, interpeter.ELSE_BRANCH)}
+	case *statements.While[interpeter.LoxValue, interpeter.RuntimeError]:
+		coverage = BranchCoverage{Line: n.SourceLine, Kind: "while", Outcomes: outcomes(interpeter.BODY_BRANCH, interpeter.SKIPPED_BRANCH)}
+	case *statements.ForIn[interpeter.LoxValue, interpeter.RuntimeError]:
+		coverage = BranchCoverage{Line: n.Keyword.Line, Column: n.Keyword.Column, Kind: "for", Outcomes: outcomes(interpeter.BODY_BRANCH, interpeter.SKIPPED_BRANCH)}
+	case *expressions.Conditional[interpeter.LoxValue, interpeter.RuntimeError]:
+		coverage = BranchCoverage{Line: n.Question.Line, Column: n.Question.Column, Kind: "?:", Outcomes: outcomes(interpeter.THEN_BRANCH, interpeter.ELSE_BRANCH)}
+	case *expressions.Logical[interpeter.LoxValue, interpeter.RuntimeError]:
+		coverage = BranchCoverage{Line: n.Operator.Line, Column: n.Operator.Column, Kind: n.Operator.Lexeme, Outcomes: outcomes(interpeter.SHORT_CIRCUIT_BRANCH, interpeter.RIGHT_BRANCH)}
+	default:
+		return nil
+	}
+
+	c.branches[node] = &coverage
+	c.branchOrder = append(c.branchOrder, &coverage)
+	return &coverage
+}
+
+func outcomes(kinds ...interpeter.BranchKind) map[interpeter.BranchKind]int {
+	outcomes := map[interpeter.BranchKind]int{}
+	for _, kind := range kinds {
+		outcomes[kind] = 0
+	}
+	return outcomes
+}
+
+// Returns the collected coverage of the script at the given path, ordered by position.
+func (c *Collector) Report(file string, source []byte) (*Report, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	absolute, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{File: absolute, SourceHash: hashSource(source), Statements: []StatementCoverage{}, Branches: []BranchCoverage{}}
+	for _, statement := range c.statementOrder {
+		report.Statements = append(report.Statements, *statement)
+	}
+	sort.SliceStable(report.Statements, func(a, b int) bool {
+		return report.Statements[a].Line < report.Statements[b].Line
+	})
+
+	for _, branch := range c.branchOrder {
+		report.Branches = append(report.Branches, *branch)
+	}
+	sort.SliceStable(report.Branches, func(a, b int) bool {
+		if report.Branches[a].Line != report.Branches[b].Line {
+			return report.Branches[a].Line < report.Branches[b].Line
+		}
+		return report.Branches[a].Column < report.Branches[b].Column
+	})
+
+	return report, nil
+}
+
+func hashSource(source []byte) string {
+	hash := sha256.Sum256(source)
+	return hex.EncodeToString(hash[:])
+}
+
+// Reads the source of the script the report was recorded for. Fails when the script changed
+// since, the recorded lines would no longer match it.
+func (r *Report) ReadSource() (string, error) {
+	source, err := os.ReadFile(r.File)
+	if err != nil {
+		return "", fmt.Errorf("Source of the coverage report not found: %s", r.File)
+	}
+
+	if hashSource(source) != r.SourceHash {
+		return "", fmt.Errorf("Source of the coverage report changed since it was recorded: %s", r.File)
+	}
+	return string(source), nil
+}
+
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func ReadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,202 @@
+package coverage
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+type lineCoverage struct {
+	// Whether any statement starts on the line.
+	executable bool
+	hits       int
+	branches   []BranchCoverage
+}
+
+func (l *lineCoverage) partial() bool {
+	for _, branch := range l.branches {
+		if !branch.complete() {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether every outcome of the branch was taken at least once.
+func (b *BranchCoverage) complete() bool {
+	for _, count := range b.Outcomes {
+		if count == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report) lines() map[int]*lineCoverage {
+	lines := map[int]*lineCoverage{}
+	line := func(number int) *lineCoverage {
+		if _, ok := lines[number]; !ok {
+			lines[number] = &lineCoverage{}
+		}
+		return lines[number]
+	}
+
+	for _, statement := range r.Statements {
+		coverage := line(statement.Line)
+		coverage.executable = true
+		coverage.hits = max(coverage.hits, statement.Hits)
+	}
+
+	for _, branch := range r.Branches {
+		coverage := line(branch.Line)
+		coverage.branches = append(coverage.branches, branch)
+	}
+
+	return lines
+}
+
+// Returns how many statements ran and how many branch outcomes were taken, out of all of them.
+func (r *Report) Summary() (statements int, totalStatements int, branches int, totalBranches int) {
+	for _, statement := range r.Statements {
+		totalStatements++
+		if statement.Hits > 0 {
+			statements++
+		}
+	}
+
+	for _, branch := range r.Branches {
+		for _, count := range branch.Outcomes {
+			totalBranches++
+			if count > 0 {
+				branches++
+			}
+		}
+	}
+
+	return statements, totalStatements, branches, totalBranches
+}
+
+func (r *Report) summaryText() string {
+	statements, totalStatements, branches, totalBranches := r.Summary()
+	return fmt.Sprintf("Statements: %d/%d (%s), branches: %d/%d (%s)",
+		statements, totalStatements, percentage(statements, totalStatements),
+		branches, totalBranches, percentage(branches, totalBranches),
+	)
+}
+
+func percentage(part int, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
+
+func describeBranch(branch BranchCoverage) string {
+	kinds := make([]string, 0, len(branch.Outcomes))
+	for kind := range branch.Outcomes {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+
+	outcomes := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		outcomes = append(outcomes, fmt.Sprintf("%s %d", kind, branch.Outcomes[interpeter.BranchKind(kind)]))
+	}
+	return fmt.Sprintf("%s: %s", branch.Kind, strings.Join(outcomes, ", "))
+}
+
+// Writes the source annotated with the hits of each line. Lines whose statements never ran are
+// marked with #####, branches with an outcome that was never taken are listed below their line.
+func (r *Report) WriteText(w io.Writer, source string) error {
+	lines := r.lines()
+
+	for index, text := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+		number := index + 1
+		hits := "-"
+		if coverage, ok := lines[number]; ok && coverage.executable {
+			hits = fmt.Sprint(coverage.hits)
+			if coverage.hits == 0 {
+				hits = "#####"
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%8s: %4d: %s\n", hits, number, text); err != nil {
+			return err
+		}
+
+		if coverage, ok := lines[number]; ok {
+			for _, branch := range coverage.branches {
+				if !branch.complete() {
+					_, _ = fmt.Fprintf(w, "%8s  %4s  branch %s\n", "", "", describeBranch(branch))
+				}
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%s\n", r.summaryText())
+	return err
+}
+
+// Writes a standalone HTML page with the source, covered lines in green, lines that never ran in
+// red and lines with a branch outcome that was never taken in yellow.
+func (r *Report) WriteHtml(w io.Writer, source string) error {
+	lines := r.lines()
+
+	var body strings.Builder
+	for index, text := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+		number := index + 1
+		class, hits, title := "", "", ""
+
+		if coverage, ok := lines[number]; ok {
+			if coverage.executable {
+				hits = fmt.Sprint(coverage.hits)
+				class = "covered"
+				if coverage.hits == 0 {
+					class = "uncovered"
+				}
+			}
+
+			if coverage.partial() && class != "uncovered" {
+				class = "partial"
+			}
+
+			var descriptions []string
+			for _, branch := range coverage.branches {
+				descriptions = append(descriptions, describeBranch(branch))
+			}
+			title = strings.Join(descriptions, "\n")
+		}
+
+		_, _ = fmt.Fprintf(&body, "<tr class=\"%s\" title=\"%s\"><td class=\"hits\">%s</td><td class=\"line\">%d</td><td><pre>%s</pre></td></tr>\n",
+			class, html.EscapeString(title), hits, number, html.EscapeString(text))
+	}
+
+	_, err := fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage of %s</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; font-family: monospace; }
+td { padding: 0 8px; vertical-align: top; }
+pre { margin: 0; }
+.hits, .line { color: #666; text-align: right; }
+.covered { background: #ddffdd; }
+.uncovered { background: #ffdddd; }
+.partial { background: #ffffcc; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+<p>%s</p>
+<table>
+%s</table>
+</body>
+</html>
+`, html.EscapeString(r.File), html.EscapeString(r.File), html.EscapeString(r.summaryText()), body.String())
+	return err
+}
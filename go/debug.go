@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lukas-reining/lox/debugger"
+	"github.com/lukas-reining/lox/lox"
+	"io"
+	"log"
+	"os"
+)
+
+func debug(loxEngine *lox.Lox, args []string) int {
+	flags := flag.NewFlagSet("debug", flag.ContinueOnError)
+	dap := flags.Bool("dap", false, "serve the Debug Adapter Protocol over stdio")
+
+	if err := flags.Parse(args); err != nil || (*dap && flags.NArg() != 0) || (!*dap && flags.NArg() != 1) {
+		fmt.Println("Usage: glox debug [script]")
+		fmt.Println("       glox debug --dap")
+		return 64
+	}
+
+	if *dap {
+		server := debugger.NewDapServer(os.Stdin, os.Stdout, func(path string, scriptDebugger *debugger.Debugger, out io.Writer, errOut io.Writer) error {
+			scriptEngine := lox.NewLoxWithOutput(out, errOut)
+			scriptEngine.AddHook(scriptDebugger)
+			return scriptEngine.RunFile(path)
+		})
+		return server.Serve()
+	}
+
+	filePath := flags.Arg(0)
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		log.Fatalf("File not found: %s", filePath)
+	}
+
+	scriptDebugger := debugger.NewDebugger(debugger.NewTerminal(os.Stdin, os.Stdout, string(source)))
+	scriptDebugger.StopOnEntry()
+	loxEngine.AddHook(scriptDebugger)
+
+	return exitCode(loxEngine.RunFile(filePath))
+}
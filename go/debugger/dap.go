@@ -0,0 +1,345 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"strconv"
+	"sync"
+)
+
+const (
+	dapThreadId          = 1
+	globalsReference     = 1
+	firstLocalsReference = 2
+)
+
+// Runs the script at path with the debugger attached. What the script prints must go to out and
+// errors must be reported to errOut, stdout belongs to the protocol.
+type Runner func(path string, debugger *Debugger, out io.Writer, errOut io.Writer) error
+
+type dapMessage struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type DapServer struct {
+	reader *bufio.Reader
+	writer io.Writer
+	run    Runner
+
+	writeMutex sync.Mutex
+	seq        int
+
+	debugger *Debugger
+	program  string
+	resume   chan Command
+
+	// Whether the script waits in Stopped. Its frames and environments may only be inspected
+	// from Serve while it does.
+	stateMutex sync.Mutex
+	stopped    bool
+}
+
+func NewDapServer(in io.Reader, out io.Writer, run Runner) *DapServer {
+	server := &DapServer{
+		reader: bufio.NewReader(in),
+		writer: out,
+		run:    run,
+		resume: make(chan Command),
+	}
+	server.debugger = NewDebugger(server)
+	return server
+}
+
+func (s *DapServer) read() (*dapMessage, error) {
+	headers, err := textproto.NewReader(s.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return nil, err
+	}
+
+	var msg dapMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+func (s *DapServer) send(msg map[string]any) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	s.seq += 1
+	msg["seq"] = s.seq
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *DapServer) respond(request *dapMessage, body any, err error) {
+	msg := map[string]any{
+		"type":        "response",
+		"request_seq": request.Seq,
+		"command":     request.Command,
+		"success":     err == nil,
+	}
+
+	if err != nil {
+		msg["message"] = err.Error()
+	} else if body != nil {
+		msg["body"] = body
+	}
+
+	s.send(msg)
+}
+
+func (s *DapServer) event(name string, body any) {
+	msg := map[string]any{"type": "event", "event": name}
+	if body != nil {
+		msg["body"] = body
+	}
+	s.send(msg)
+}
+
+// Forwards everything written to it to the client as output events of the category.
+type dapOutput struct {
+	server   *DapServer
+	category string
+}
+
+func (o *dapOutput) Write(p []byte) (int, error) {
+	o.server.event("output", map[string]any{"category": o.category, "output": string(p)})
+	return len(p), nil
+}
+
+func (s *DapServer) Stopped(debugger *Debugger, reason StopReason, line int) Command {
+	s.stateMutex.Lock()
+	s.stopped = true
+	s.stateMutex.Unlock()
+
+	s.event("stopped", map[string]any{"reason": string(reason), "threadId": dapThreadId, "allThreadsStopped": true})
+	return <-s.resume
+}
+
+func (s *DapServer) isStopped() bool {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	return s.stopped
+}
+
+// Marks the script as running again, fails when it isn't stopped.
+func (s *DapServer) claimStop() error {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
+	if !s.stopped {
+		return fmt.Errorf("program is not stopped")
+	}
+	s.stopped = false
+	return nil
+}
+
+// Answers the request and hands the command to the script waiting in Stopped.
+func (s *DapServer) resumeWith(request *dapMessage, body any, command Command) {
+	if err := s.claimStop(); err != nil {
+		s.respond(request, nil, err)
+		return
+	}
+
+	s.respond(request, body, nil)
+	s.resume <- command
+}
+
+func (s *DapServer) source() map[string]any {
+	return map[string]any{"path": s.program}
+}
+
+func (s *DapServer) variables(variables []Variable) []map[string]any {
+	result := []map[string]any{}
+	for _, variable := range variables {
+		result = append(result, map[string]any{"name": variable.Name, "value": variable.Value, "variablesReference": 0})
+	}
+	return result
+}
+
+func (s *DapServer) launch() {
+	go func() {
+		exitCode := 0
+		out := &dapOutput{server: s, category: "stdout"}
+		errOut := &dapOutput{server: s, category: "stderr"}
+		if err := s.run(s.program, s.debugger, out, errOut); err != nil {
+			exitCode = 1
+		}
+
+		s.event("exited", map[string]any{"exitCode": exitCode})
+		s.event("terminated", nil)
+	}()
+}
+
+func (s *DapServer) Serve() int {
+	for {
+		request, err := s.read()
+		if err != nil {
+			return 1
+		}
+
+		if request.Type != "request" {
+			continue
+		}
+
+		switch request.Command {
+		case "stackTrace", "scopes", "variables", "evaluate":
+			if !s.isStopped() {
+				s.respond(request, nil, fmt.Errorf("program is not stopped"))
+				continue
+			}
+		}
+
+		switch request.Command {
+		case "initialize":
+			s.respond(request, map[string]any{
+				"supportsConfigurationDoneRequest": true,
+				"supportsEvaluateForHovers":        true,
+			}, nil)
+			s.event("initialized", nil)
+		case "launch":
+			var args struct {
+				Program     string `json:"program"`
+				StopOnEntry bool   `json:"stopOnEntry"`
+			}
+			err := json.Unmarshal(request.Arguments, &args)
+			if err == nil {
+				if _, statErr := os.Stat(args.Program); statErr != nil {
+					err = statErr
+				}
+			}
+			if err == nil {
+				s.program = args.Program
+				if args.StopOnEntry {
+					s.debugger.StopOnEntry()
+				}
+			}
+			s.respond(request, nil, err)
+		case "setBreakpoints":
+			var args struct {
+				Breakpoints []struct {
+					Line int `json:"line"`
+				} `json:"breakpoints"`
+			}
+			if err := json.Unmarshal(request.Arguments, &args); err != nil {
+				s.respond(request, nil, err)
+				continue
+			}
+
+			var lines []int
+			breakpoints := []map[string]any{}
+			for _, breakpoint := range args.Breakpoints {
+				lines = append(lines, breakpoint.Line)
+				breakpoints = append(breakpoints, map[string]any{"verified": true, "line": breakpoint.Line, "source": s.source()})
+			}
+			s.debugger.SetBreakpoints(lines)
+			s.respond(request, map[string]any{"breakpoints": breakpoints}, nil)
+		case "configurationDone":
+			if s.program == "" {
+				s.respond(request, nil, fmt.Errorf("configurationDone before launch"))
+				continue
+			}
+			s.respond(request, nil, nil)
+			s.launch()
+		case "threads":
+			s.respond(request, map[string]any{"threads": []map[string]any{{"id": dapThreadId, "name": "main"}}}, nil)
+		case "stackTrace":
+			frames := []map[string]any{}
+			for _, frame := range s.debugger.Frames() {
+				frames = append(frames, map[string]any{
+					"id":     frame.Index,
+					"name":   frame.Name,
+					"line":   frame.Line,
+					"column": 1,
+					"source": s.source(),
+				})
+			}
+			s.respond(request, map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil)
+		case "scopes":
+			var args struct {
+				FrameId int `json:"frameId"`
+			}
+			err := json.Unmarshal(request.Arguments, &args)
+			s.respond(request, map[string]any{"scopes": []map[string]any{
+				{"name": "Locals", "variablesReference": firstLocalsReference + args.FrameId, "expensive": false},
+				{"name": "Globals", "variablesReference": globalsReference, "expensive": false},
+			}}, err)
+		case "variables":
+			var args struct {
+				VariablesReference int `json:"variablesReference"`
+			}
+			err := json.Unmarshal(request.Arguments, &args)
+
+			var variables []Variable
+			if args.VariablesReference == globalsReference {
+				variables = s.debugger.Globals()
+			} else {
+				variables = s.debugger.Locals(args.VariablesReference - firstLocalsReference)
+			}
+			s.respond(request, map[string]any{"variables": s.variables(variables)}, err)
+		case "evaluate":
+			var args struct {
+				Expression string `json:"expression"`
+				FrameId    *int   `json:"frameId"`
+			}
+			if err := json.Unmarshal(request.Arguments, &args); err != nil {
+				s.respond(request, nil, err)
+				continue
+			}
+
+			frame := 0
+			if args.FrameId != nil {
+				frame = *args.FrameId
+			} else if frames := s.debugger.Frames(); len(frames) > 0 {
+				frame = frames[0].Index
+			}
+
+			value, err := s.debugger.Evaluate(args.Expression, frame)
+			s.respond(request, map[string]any{"result": value, "variablesReference": 0}, err)
+		case "continue":
+			s.resumeWith(request, map[string]any{"allThreadsContinued": true}, CONTINUE)
+		case "next":
+			s.resumeWith(request, nil, STEP_OVER)
+		case "stepIn":
+			s.resumeWith(request, nil, STEP_IN)
+		case "stepOut":
+			s.resumeWith(request, nil, STEP_OUT)
+		case "pause":
+			s.debugger.Pause()
+			s.respond(request, nil, nil)
+		case "disconnect":
+			s.respond(request, nil, nil)
+			if s.claimStop() == nil {
+				s.resume <- QUIT
+			}
+			return 0
+		default:
+			s.respond(request, nil, fmt.Errorf("unsupported request '%s'", request.Command))
+		}
+	}
+}
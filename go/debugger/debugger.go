@@ -0,0 +1,288 @@
+package debugger
+
+import (
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser"
+	"github.com/lukas-reining/lox/parser/statements"
+	"github.com/lukas-reining/lox/scanner"
+	"sort"
+	"sync"
+)
+
+type Command string
+
+const (
+	CONTINUE  Command = "continue"
+	STEP_IN   Command = "stepIn"
+	STEP_OVER Command = "stepOver"
+	STEP_OUT  Command = "stepOut"
+	QUIT      Command = "quit"
+)
+
+type StopReason string
+
+const (
+	ENTRY      StopReason = "entry"
+	BREAKPOINT StopReason = "breakpoint"
+	STEP       StopReason = "step"
+	PAUSE      StopReason = "pause"
+)
+
+type Frontend interface {
+	Stopped(debugger *Debugger, reason StopReason, line int) Command
+}
+
+type Frame struct {
+	Index int
+	Name  string
+	Line  int
+}
+
+type Variable struct {
+	Name  string
+	Value string
+}
+
+type Debugger struct {
+	interpeter.BaseHook
+
+	frontend    Frontend
+	interpreter *interpeter.Interpreter
+	evaluating  bool
+
+	mutex        sync.Mutex
+	breakpoints  map[int]bool
+	command      Command
+	commandDepth int
+	paused       bool
+
+	// The statements nested in the last stop that share its line and depth. Their first run
+	// belongs to the stop, so it doesn't stop again, later runs like loop iterations do.
+	nested      map[any]bool
+	nestedDepth int
+}
+
+func NewDebugger(frontend Frontend) *Debugger {
+	return &Debugger{
+		frontend:    frontend,
+		breakpoints: map[int]bool{},
+		command:     CONTINUE,
+	}
+}
+
+func (d *Debugger) StopOnEntry() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.command = STEP_IN
+	d.commandDepth = 0
+}
+
+func (d *Debugger) SetBreakpoint(line int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.breakpoints[line] = true
+}
+
+func (d *Debugger) ClearBreakpoint(line int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.breakpoints, line)
+}
+
+func (d *Debugger) SetBreakpoints(lines []int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.breakpoints = map[int]bool{}
+	for _, line := range lines {
+		d.breakpoints[line] = true
+	}
+}
+
+func (d *Debugger) Breakpoints() []int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var lines []int
+	for line := range d.breakpoints {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func (d *Debugger) Pause() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.paused = true
+}
+
+func (d *Debugger) stopReason(line int, depth int) StopReason {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.paused {
+		d.paused = false
+		return PAUSE
+	}
+
+	if d.breakpoints[line] {
+		return BREAKPOINT
+	}
+
+	switch d.command {
+	case STEP_IN:
+		if d.commandDepth == 0 {
+			return ENTRY
+		}
+		return STEP
+	case STEP_OVER:
+		if depth <= d.commandDepth {
+			return STEP
+		}
+	case STEP_OUT:
+		if depth < d.commandDepth {
+			return STEP
+		}
+	}
+
+	return ""
+}
+
+func (d *Debugger) BeforeStatement(interpreter *interpeter.Interpreter, statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) interpeter.RuntimeError {
+	if d.evaluating {
+		return nil
+	}
+
+	d.interpreter = interpreter
+	line := statement.Line()
+	depth := len(interpreter.Frames())
+
+	if depth == d.nestedDepth && d.nested[statement] {
+		delete(d.nested, statement)
+		return nil
+	}
+	if depth <= d.nestedDepth {
+		d.nested = nil
+	}
+
+	reason := d.stopReason(line, depth)
+	if reason == "" {
+		return nil
+	}
+
+	d.nested, d.nestedDepth = nestedStatements(statement), depth
+
+	command := d.frontend.Stopped(d, reason, line)
+	if command == QUIT {
+		return interpeter.NewRuntimeError(line, "Execution stopped by debugger.")
+	}
+
+	d.mutex.Lock()
+	d.command = command
+	d.commandDepth = depth
+	d.mutex.Unlock()
+
+	return nil
+}
+
+func nestedStatements(statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) map[any]bool {
+	nested := map[any]bool{}
+	interpeter.WalkSyntaxTree([]statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]{statement}, func(node any) {
+		if inner, ok := node.(statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]); ok && node != any(statement) && inner.Line() == statement.Line() {
+			nested[node] = true
+		}
+	})
+	return nested
+}
+
+func (d *Debugger) Frames() []Frame {
+	var frames []Frame
+	if d.interpreter == nil {
+		return frames
+	}
+
+	callFrames := d.interpreter.Frames()
+	for index := len(callFrames) - 1; index >= 0; index-- {
+		frames = append(frames, Frame{Index: index, Name: callFrames[index].Name, Line: callFrames[index].Line})
+	}
+	return frames
+}
+
+// Converts a value the way print does. A user defined toString runs without stopping at its
+// statements.
+func (d *Debugger) stringify(value interpeter.LoxValue) string {
+	evaluating := d.evaluating
+	d.evaluating = true
+	text, err := d.interpreter.Stringify(value)
+	d.evaluating = evaluating
+
+	if err != nil {
+		return interpeter.Stringify(value)
+	}
+	return text
+}
+
+func (d *Debugger) variables(env *interpeter.Environment) []Variable {
+	var vars []Variable
+	for _, name := range env.Names() {
+		value, _ := env.Lookup(name)
+		vars = append(vars, Variable{Name: name, Value: d.stringify(value)})
+	}
+	return vars
+}
+
+func (d *Debugger) Locals(frame int) []Variable {
+	var locals []Variable
+	if d.interpreter == nil || frame < 0 || frame >= len(d.interpreter.Frames()) {
+		return locals
+	}
+
+	seen := map[string]bool{}
+	globals := d.interpreter.Globals()
+	for env := d.interpreter.FrameEnvironment(frame); env != nil && env != globals; env = env.Enclosing() {
+		for _, variable := range d.variables(env) {
+			// Inner scopes shadow outer ones.
+			if !seen[variable.Name] {
+				seen[variable.Name] = true
+				locals = append(locals, variable)
+			}
+		}
+	}
+	return locals
+}
+
+func (d *Debugger) Globals() []Variable {
+	if d.interpreter == nil {
+		return nil
+	}
+
+	return d.variables(d.interpreter.Globals())
+}
+
+func (d *Debugger) Evaluate(source string, frame int) (string, error) {
+	if d.interpreter == nil {
+		return "", interpeter.NewRuntimeError(0, "Program is not running.")
+	}
+
+	sourceScanner := scanner.NewScanner(source)
+	tokens, err := sourceScanner.ScanTokens()
+	if err != nil {
+		return "", err
+	}
+
+	expression, parseErr := parser.NewParser[any, interpeter.RuntimeError](tokens).ParseExpression()
+	if parseErr != nil {
+		return "", parseErr
+	}
+
+	d.evaluating = true
+	value, evalErr := d.interpreter.EvaluateInFrame(expression, frame)
+	d.evaluating = false
+
+	if evalErr != nil {
+		return "", evalErr
+	}
+
+	return d.stringify(value), nil
+}
@@ -0,0 +1,140 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const terminalHelp = `Commands:
+  c, continue         run until the next breakpoint
+  s, step             step into the next statement
+  n, next             step over function calls
+  o, out              step out of the current function
+  b, break [line]     set a breakpoint or list all breakpoints
+  d, delete <line>    remove a breakpoint
+  bt, backtrace       show the call stack
+  l, locals [frame]   show local variables of a frame
+  g, globals          show global variables
+  p, print <expr>     evaluate an expression in the current frame
+  q, quit             stop the program`
+
+type Terminal struct {
+	in    *bufio.Reader
+	out   io.Writer
+	lines []string
+	frame int
+}
+
+func NewTerminal(in io.Reader, out io.Writer, source string) *Terminal {
+	return &Terminal{
+		in:    bufio.NewReader(in),
+		out:   out,
+		lines: strings.Split(source, "\n"),
+	}
+}
+
+func (t *Terminal) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(t.out, format, args...)
+}
+
+func (t *Terminal) sourceLine(line int) string {
+	if line < 1 || line > len(t.lines) {
+		return ""
+	}
+	return strings.TrimSpace(t.lines[line-1])
+}
+
+func (t *Terminal) printVariables(variables []Variable) {
+	if len(variables) == 0 {
+		t.printf("  <none>\n")
+	}
+
+	for _, variable := range variables {
+		t.printf("  %s = %s\n", variable.Name, variable.Value)
+	}
+}
+
+func (t *Terminal) Stopped(debugger *Debugger, reason StopReason, line int) Command {
+	t.printf("Stopped (%s) at line %d: %s\n", reason, line, t.sourceLine(line))
+	t.frame = len(debugger.Frames()) - 1
+
+	for {
+		t.printf("(debug) ")
+		text, err := t.in.ReadString('\n')
+		if err != nil {
+			return QUIT
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "c", "continue":
+			return CONTINUE
+		case "s", "step":
+			return STEP_IN
+		case "n", "next":
+			return STEP_OVER
+		case "o", "out":
+			return STEP_OUT
+		case "q", "quit":
+			return QUIT
+		case "b", "break", "d", "delete":
+			if len(fields) == 1 && (fields[0] == "b" || fields[0] == "break") {
+				for _, breakpoint := range debugger.Breakpoints() {
+					t.printf("  line %d\n", breakpoint)
+				}
+				continue
+			}
+
+			if len(fields) != 2 {
+				t.printf("Expected a line number.\n")
+				continue
+			}
+
+			breakpoint, err := strconv.Atoi(fields[1])
+			if err != nil {
+				t.printf("Invalid line number '%s'.\n", fields[1])
+				continue
+			}
+
+			if fields[0] == "b" || fields[0] == "break" {
+				debugger.SetBreakpoint(breakpoint)
+				t.printf("Breakpoint set at line %d.\n", breakpoint)
+			} else {
+				debugger.ClearBreakpoint(breakpoint)
+				t.printf("Breakpoint removed from line %d.\n", breakpoint)
+			}
+		case "bt", "backtrace":
+			for _, frame := range debugger.Frames() {
+				t.printf("  #%d %s (line %d)\n", frame.Index, frame.Name, frame.Line)
+			}
+		case "l", "locals":
+			frame := t.frame
+			if len(fields) > 1 {
+				if index, err := strconv.Atoi(fields[1]); err == nil {
+					frame = index
+				}
+			}
+			t.printVariables(debugger.Locals(frame))
+		case "g", "globals":
+			t.printVariables(debugger.Globals())
+		case "p", "print":
+			expression := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), fields[0]))
+			if value, err := debugger.Evaluate(expression, t.frame); err != nil {
+				t.printf("Error: %s\n", err.Error())
+			} else {
+				t.printf("%s\n", value)
+			}
+		case "h", "help":
+			t.printf("%s\n", terminalHelp)
+		default:
+			t.printf("Unknown command '%s'. Type 'help' for a list of commands.\n", fields[0])
+		}
+	}
+}
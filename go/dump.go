@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lukas-reining/lox/lox"
+)
+
+func dump(loxEngine *lox.Lox, args []string) int {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	tokens := flags.Bool("tokens", false, "dump the scanned tokens")
+	ast := flags.Bool("ast", false, "dump the parsed statement tree")
+	asJson := flags.Bool("json", false, "emit JSON instead of plain text")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *tokens == *ast || (*ast && !*asJson) {
+		fmt.Println("Usage: glox dump --tokens [--json] [script]")
+		fmt.Println("       glox dump --ast --json [script]")
+		return 64
+	}
+
+	mode := lox.DUMP_TOKENS
+	if *ast {
+		mode = lox.DUMP_AST
+	}
+
+	return exitCode(loxEngine.DumpFile(flags.Arg(0), mode, *asJson))
+}
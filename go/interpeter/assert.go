@@ -0,0 +1,119 @@
+package interpeter
+
+import (
+	"fmt"
+)
+
+// An AssertionError is raised by the assertion natives, so that a failed assertion can be told
+// apart from any other runtime error.
+type AssertionError struct {
+	*BaseRuntimeError
+}
+
+func NewAssertionError(line int, message string) *AssertionError {
+	return &AssertionError{BaseRuntimeError: &BaseRuntimeError{line: line, message: message}}
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("[line %d] AssertionError: %v", e.Line(), e.Message())
+}
+
+func (i *Interpreter) assertionError(message string) RuntimeError {
+	return NewAssertionError(i.currentFrame().Line, message)
+}
+
+// Returns the optional message argument of an assertion, or the default message.
+func (i *Interpreter) assertionMessage(name string, args []LoxValue, count int, message string) (string, RuntimeError) {
+	if len(args) < count || len(args) > count+1 {
+		return "", i.nativeError(fmt.Sprintf("Expected %d or %d arguments but got %d.", count, count+1, len(args)))
+	}
+
+	if len(args) == count+1 {
+		return i.stringArgument(name, args[count])
+	}
+	return message, nil
+}
+
+// Like isEqual, but compares lists by their elements.
+func valuesEqual(left LoxValue, right LoxValue) bool {
+	leftList, leftIsList := left.(*LoxList)
+	rightList, rightIsList := right.(*LoxList)
+	if !leftIsList || !rightIsList {
+		return isEqual(left, right)
+	}
+
+	if len(leftList.Elements) != len(rightList.Elements) {
+		return false
+	}
+	for index := range leftList.Elements {
+		if !valuesEqual(leftList.Elements[index], rightList.Elements[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *Interpreter) describe(value LoxValue) (string, RuntimeError) {
+	if text, ok := value.(string); ok {
+		return fmt.Sprintf("%q", text), nil
+	}
+	return i.stringify(value)
+}
+
+func defineAssertGlobals(globals *Environment) {
+	globals.define("assert", NewLoxCallable(VARIADIC_ARITY, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		message, err := interpreter.assertionMessage("assert", args, 1, "Assertion failed.")
+		if err != nil {
+			return nil, err
+		}
+
+		if !isTruthy(args[0]) {
+			return nil, interpreter.assertionError(message)
+		}
+		return nil, nil
+	}))
+
+	// Called with the expected value first, like assertEqual(3, add(1, 2)).
+	globals.define("assertEqual", NewLoxCallable(VARIADIC_ARITY, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		message, err := interpreter.assertionMessage("assertEqual", args, 2, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if valuesEqual(args[0], args[1]) {
+			return nil, nil
+		}
+
+		expected, err := interpreter.describe(args[0])
+		if err != nil {
+			return nil, err
+		}
+		actual, err := interpreter.describe(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if message == "" {
+			return nil, interpreter.assertionError(fmt.Sprintf("Expected %s but got %s.", expected, actual))
+		}
+		return nil, interpreter.assertionError(fmt.Sprintf("%s Expected %s but got %s.", message, expected, actual))
+	}))
+
+	// Calls the function and returns the message of the runtime error it raised.
+	globals.define("assertThrows", NewLoxCallable(VARIADIC_ARITY, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		message, err := interpreter.assertionMessage("assertThrows", args, 1, "Expected function to throw.")
+		if err != nil {
+			return nil, err
+		}
+
+		callback, isCallable := args[0].(Callable)
+		if !isCallable || callback.Arity() != 0 {
+			return nil, interpreter.nativeError("Argument to 'assertThrows' must be a function without parameters.")
+		}
+
+		if _, err := callback.Call(interpreter, []LoxValue{}); err != nil {
+			return err.Message(), nil
+		}
+		return nil, interpreter.assertionError(message)
+	}))
+}
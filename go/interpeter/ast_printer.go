@@ -36,6 +36,8 @@ func (a *AstPrinter) VisitLiteralExpression(exp *expressions.Literal[string, Run
 		return value, nil
 	case int:
 		return strconv.Itoa(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
 	case float32:
 		return fmt.Sprintf("%f", value), nil
 	case float64:
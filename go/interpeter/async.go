@@ -0,0 +1,106 @@
+package interpeter
+
+import (
+	"time"
+)
+
+// What an async fiber hands to the event loop when it awaits a pending promise.
+type awaitRequest struct {
+	promise *LoxPromise
+}
+
+// Starts an async function body on its own fiber, running it until its first await.
+func (i *Interpreter) callAsync(function *LoxFunction, env *Environment, args []LoxValue) *LoxPromise {
+	promise := NewLoxPromise()
+
+	fiber := newLoxFiber(i, env, func(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError) {
+		return function.execute(interpreter, env, args)
+	})
+	fiber.coroutine.async = true
+
+	i.loop.stepAsync(fiber, promise)
+	return promise
+}
+
+func (l *eventLoop) stepAsync(fiber *LoxFiber, promise *LoxPromise) {
+	step := fiber.coroutine.transfer(nil)
+	if step.done {
+		promise.settle(l, step.value, step.err)
+		return
+	}
+
+	awaited := step.value.(*awaitRequest).promise
+	awaited.then(l, func() RuntimeError {
+		l.stepAsync(fiber, promise)
+		return nil
+	})
+}
+
+func (i *Interpreter) await(promise *LoxPromise) (LoxValue, RuntimeError) {
+	if i.fiber != nil && i.fiber.async {
+		if !promise.settled {
+			i.fiber.suspend(&awaitRequest{promise: promise})
+		}
+		promise.handled = true
+		return promise.value, promise.err
+	}
+
+	// Outside of async functions awaiting blocks on the event loop.
+	promise.handled = true
+	if err := i.loop.runUntil(promise.isSettled); err != nil {
+		return nil, err
+	}
+
+	if !promise.settled {
+		return nil, i.nativeError("Awaited promise can never settle.")
+	}
+	return promise.value, promise.err
+}
+
+func (i *Interpreter) durationArgument(name string, value LoxValue) (time.Duration, RuntimeError) {
+	switch milliseconds := value.(type) {
+	case int64:
+		return time.Duration(milliseconds) * time.Millisecond, nil
+	case float64:
+		return time.Duration(milliseconds * float64(time.Millisecond)), nil
+	}
+	return 0, i.nativeError("Delay passed to '" + name + "' must be a number of milliseconds.")
+}
+
+func defineAsyncGlobals(globals *Environment) {
+	globals.define("Fiber", &LoxFiberClass{})
+
+	globals.define("setTimeout", NewLoxCallable(2, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		callback, isCallable := args[0].(Callable)
+		if !isCallable || callback.Arity() != 0 {
+			return nil, interpreter.nativeError("Callback passed to 'setTimeout' must be a function without parameters.")
+		}
+
+		delay, err := interpreter.durationArgument("setTimeout", args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		loopInterpreter := interpreter.fork("<timer>", interpreter.globals)
+		loopInterpreter.generator, loopInterpreter.fiber = nil, nil
+		return interpreter.loop.schedule(delay, func() RuntimeError {
+			_, err := callback.Call(loopInterpreter, nil)
+			return err
+		}), nil
+	}))
+
+	globals.define("sleep", NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		delay, err := interpreter.durationArgument("sleep", args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		promise := NewLoxPromise()
+		loop := interpreter.loop
+		loop.schedule(delay, func() RuntimeError {
+			promise.settle(loop, nil, nil)
+			return nil
+		})
+		return promise, nil
+	}))
+}
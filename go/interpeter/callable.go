@@ -8,6 +8,9 @@ type Callable interface {
 	Call(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError)
 }
 
+// Natives with this arity check their argument count themselves.
+const VARIADIC_ARITY = -1
+
 type LoxCallable struct {
 	Callable
 	arity int
@@ -56,20 +59,39 @@ func NewLoxFunction(declaration statements.Function[LoxValue, RuntimeError], clo
 func (f *LoxFunction) Call(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
 	environment := NewEnvironment(f.closure)
 
+	if f.declaration.Generator {
+		return NewLoxGenerator(interpreter, f, environment, args), nil
+	}
+
+	if f.declaration.Async {
+		return interpreter.callAsync(f, environment, args), nil
+	}
+
+	return f.execute(interpreter, environment, args)
+}
+
+// Runs the body in the environment, binding the parameters once the call has been entered.
+func (f *LoxFunction) execute(interpreter *Interpreter, environment *Environment, args []LoxValue) (LoxValue, RuntimeError) {
+	interpreter.enterFunction(f.declaration.Name.Lexeme, f.declaration.Name.Line, args)
 	for index, token := range f.declaration.Params {
 		environment.define(token.Lexeme, args[index])
 	}
-
 	value, err := interpreter.executeBlock(f.declaration.Body, environment)
+	if returnValue, isReturn := value.(*ReturnValue); isReturn {
+		value = returnValue.Value
+	}
+
+	if err == nil && f.isInitializer {
+		value = f.closure.getAt(0, "this")
+	}
+
+	interpreter.exitFunction(value, err)
+
 	if err != nil {
 		return nil, err
 	}
 
-	if f.isInitializer {
-		return f.closure.getAt(0, "this"), nil
-	} else {
-		return value, nil
-	}
+	return value, nil
 }
 
 func (f *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
@@ -8,14 +8,20 @@ import (
 type LoxClass struct {
 	Stringifyable
 	Callable
-	Name    string
-	Methods map[string]LoxFunction
+	Name          string
+	Methods       map[string]LoxFunction
+	StaticMethods map[string]LoxFunction
+	Getters       map[string]LoxFunction
+	Setters       map[string]LoxFunction
 }
 
 func NewLoxClass(name string, methods map[string]LoxFunction) *LoxClass {
 	return &LoxClass{
-		Name:    name,
-		Methods: methods,
+		Name:          name,
+		Methods:       methods,
+		StaticMethods: map[string]LoxFunction{},
+		Getters:       map[string]LoxFunction{},
+		Setters:       map[string]LoxFunction{},
 	}
 }
 
@@ -41,6 +47,14 @@ func (c *LoxClass) Arity() int {
 	}
 }
 
+func (c *LoxClass) get(name scanner.Token) (LoxValue, RuntimeError) {
+	if method, hasMethod := c.StaticMethods[name.Lexeme]; hasMethod {
+		return &method, nil
+	}
+
+	return nil, NewRuntimeError(name.Line, fmt.Sprintf("Undefined static method '%s'.", name.Lexeme))
+}
+
 func (c *LoxClass) ToString() string {
 	return c.Name
 }
@@ -49,16 +63,30 @@ type LoxInstance struct {
 	Stringifyable
 	Class  *LoxClass
 	Fields map[string]LoxValue
+	Frozen bool
+
+	// Properties whose getter or setter is running. Inside its own accessor a property is the
+	// field backing it, so a setter can store the value without calling itself again.
+	getting map[string]bool
+	setting map[string]bool
 }
 
 func NewLoxInstance(class *LoxClass) *LoxInstance {
 	return &LoxInstance{
-		Class:  class,
-		Fields: make(map[string]LoxValue),
+		Class:   class,
+		Fields:  make(map[string]LoxValue),
+		getting: map[string]bool{},
+		setting: map[string]bool{},
 	}
 }
 
-func (c *LoxInstance) get(name scanner.Token) (LoxValue, RuntimeError) {
+func (c *LoxInstance) get(interpreter *Interpreter, name scanner.Token) (LoxValue, RuntimeError) {
+	if getter, hasGetter := c.Class.Getters[name.Lexeme]; hasGetter && !c.getting[name.Lexeme] {
+		c.getting[name.Lexeme] = true
+		defer delete(c.getting, name.Lexeme)
+		return getter.Bind(c).Call(interpreter, nil)
+	}
+
 	if value, hasValue := c.Fields[name.Lexeme]; hasValue {
 		return value, nil
 	}
@@ -68,11 +96,23 @@ func (c *LoxInstance) get(name scanner.Token) (LoxValue, RuntimeError) {
 		return scopedMethod, nil
 	}
 
-	return NewRuntimeError(name.Line, fmt.Sprintf("Undefined property '%s'.", name.Lexeme)), nil
+	return nil, NewRuntimeError(name.Line, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
 }
 
-func (c *LoxInstance) set(name scanner.Token, value LoxValue) {
+func (c *LoxInstance) set(interpreter *Interpreter, name scanner.Token, value LoxValue) RuntimeError {
+	if c.Frozen {
+		return NewRuntimeError(name.Line, "Can't modify frozen instance of '"+c.Class.Name+"'.")
+	}
+
+	if setter, hasSetter := c.Class.Setters[name.Lexeme]; hasSetter && !c.setting[name.Lexeme] {
+		c.setting[name.Lexeme] = true
+		defer delete(c.setting, name.Lexeme)
+		_, err := setter.Bind(c).Call(interpreter, []LoxValue{value})
+		return err
+	}
+
 	c.Fields[name.Lexeme] = value
+	return nil
 }
 
 func (c *LoxInstance) ToString() string {
@@ -0,0 +1,119 @@
+package interpeter
+
+import "runtime"
+
+type coroutineStep struct {
+	value LoxValue
+	done  bool
+	err   RuntimeError
+}
+
+// A coroutine runs a body on its own goroutine with its own interpreter state, so the
+// environment and call stack of a suspended coroutine are kept apart from its caller's.
+// Control is handed back and forth explicitly, so only one of them runs at a time.
+// A coroutine that is never run to the end keeps its goroutine parked until it is cancelled,
+// which the event loop does once the generator or fiber becomes unreachable or the interpreter
+// is closed.
+type coroutine struct {
+	interpreter *Interpreter
+	body        func(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError)
+	// Async coroutines run the body of an async function and only suspend to await promises.
+	async bool
+
+	started bool
+	running bool
+	done    bool
+
+	resumes chan LoxValue
+	steps   chan coroutineStep
+	// Closed to cancel the coroutine while it is suspended, and by its goroutine when it exits.
+	cancelled chan struct{}
+	exited    chan struct{}
+}
+
+func newCoroutine(body func(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError)) *coroutine {
+	return &coroutine{
+		body:      body,
+		resumes:   make(chan LoxValue),
+		steps:     make(chan coroutineStep),
+		cancelled: make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+}
+
+// Creates the interpreter state for a coroutine, sharing everything but the environment and call stack.
+func (i *Interpreter) fork(name string, env *Environment) *Interpreter {
+	return &Interpreter{
+		globals:   i.globals,
+		env:       env,
+		locals:    i.locals,
+		hooks:     i.hooks,
+		frames:    []*CallFrame{{Name: name}},
+		out:       i.out,
+		loop:      i.loop,
+		generator: i.generator,
+		fiber:     i.fiber,
+	}
+}
+
+func (c *coroutine) run(value LoxValue) {
+	defer close(c.exited)
+	result, err := c.body(c.interpreter, value)
+	c.steps <- coroutineStep{value: result, done: true, err: err}
+}
+
+// Resumes the coroutine with a value and waits until it suspends or finishes.
+func (c *coroutine) transfer(value LoxValue) coroutineStep {
+	loop := c.interpreter.loop
+	loop.cancelAbandoned()
+
+	caller := loop.running
+	loop.running = c.interpreter
+	defer func() { loop.running = caller }()
+
+	c.running = true
+	if c.started {
+		c.resumes <- value
+	} else {
+		c.started = true
+		loop.coroutines[c] = true
+		go c.run(value)
+	}
+
+	step := <-c.steps
+	c.running = false
+	c.done = step.done
+	if c.done {
+		delete(loop.coroutines, c)
+	}
+	return step
+}
+
+// Called from inside the coroutine to hand a value to whoever resumed it.
+func (c *coroutine) suspend(value LoxValue) LoxValue {
+	c.steps <- coroutineStep{value: value}
+
+	select {
+	case value := <-c.resumes:
+		return value
+	case <-c.cancelled:
+		// Unwinds the goroutine without returning to the body, so no Lox code runs anymore.
+		runtime.Goexit()
+		return nil
+	}
+}
+
+// Ends a suspended coroutine and waits until its goroutine has exited. Coroutines that never
+// started have no goroutine and are only marked as done.
+func (c *coroutine) cancel() {
+	if c.running || c.done {
+		return
+	}
+
+	c.done = true
+	delete(c.interpreter.loop.coroutines, c)
+	if c.started {
+		close(c.cancelled)
+		<-c.exited
+	}
+}
@@ -2,25 +2,52 @@ package interpeter
 
 import (
 	"github.com/lukas-reining/lox/scanner"
+	"sort"
 )
 
 type Environment struct {
 	values    map[string]LoxValue
+	constants map[string]bool
 	level     int
 	enclosing *Environment
+
+	// Told about every variable that is defined or assigned in the environment or the ones
+	// created from it.
+	watcher func(name string, value LoxValue, defined bool)
 }
 
 func NewEnvironment(enclosing *Environment) *Environment {
 	level := 0
+	var watcher func(name string, value LoxValue, defined bool)
 	if enclosing != nil {
 		level = enclosing.level + 1
+		watcher = enclosing.watcher
 	}
 
 	return &Environment{
 		values:    make(map[string]LoxValue),
 		level:     level,
 		enclosing: enclosing,
+		watcher:   watcher,
+	}
+}
+
+func (e *Environment) Names() []string {
+	var names []string
+	for name := range e.values {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Environment) Lookup(name string) (LoxValue, bool) {
+	value, ok := e.values[name]
+	return value, ok
+}
+
+func (e *Environment) Enclosing() *Environment {
+	return e.enclosing
 }
 
 func (e *Environment) hasParent() bool {
@@ -29,6 +56,21 @@ func (e *Environment) hasParent() bool {
 
 func (e *Environment) define(name string, value LoxValue) {
 	e.values[name] = value
+	e.changed(name, value, true)
+}
+
+func (e *Environment) defineConstant(name string, value LoxValue) {
+	if e.constants == nil {
+		e.constants = map[string]bool{}
+	}
+
+	e.values[name] = value
+	e.constants[name] = true
+	e.changed(name, value, true)
+}
+
+func (e *Environment) isConstant(name string) bool {
+	return e.constants[name]
 }
 
 func (e *Environment) assign(name scanner.Token, value LoxValue) RuntimeError {
@@ -40,13 +82,31 @@ func (e *Environment) assign(name scanner.Token, value LoxValue) RuntimeError {
 		return NewRuntimeError(name.Line, "Undefined variable '"+name.Lexeme+"'.")
 	}
 
+	if e.isConstant(name.Lexeme) {
+		return NewRuntimeError(name.Line, "Can't assign to constant '"+name.Lexeme+"'.")
+	}
+
 	e.values[name.Lexeme] = value
+	e.changed(name.Lexeme, value, false)
 	return nil
 }
 
-func (e *Environment) assignAt(distance int, name scanner.Token, value LoxValue) {
+func (e *Environment) assignAt(distance int, name scanner.Token, value LoxValue) RuntimeError {
 	env := e.ancestor(distance)
+
+	if env.isConstant(name.Lexeme) {
+		return NewRuntimeError(name.Line, "Can't assign to constant '"+name.Lexeme+"'.")
+	}
+
 	env.values[name.Lexeme] = value
+	env.changed(name.Lexeme, value, false)
+	return nil
+}
+
+func (e *Environment) changed(name string, value LoxValue, defined bool) {
+	if e.watcher != nil {
+		e.watcher(name, value, defined)
+	}
 }
 
 func (e *Environment) exists(name scanner.Token) bool {
@@ -0,0 +1,111 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type LoxFiber struct {
+	Stringifyable
+	coroutine *coroutine
+}
+
+func newLoxFiber(interpreter *Interpreter, env *Environment, body func(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError)) *LoxFiber {
+	fiber := &LoxFiber{coroutine: newCoroutine(body)}
+	fiber.coroutine.interpreter = interpreter.fork("<fiber>", env)
+	fiber.coroutine.interpreter.generator = nil
+	fiber.coroutine.interpreter.fiber = fiber.coroutine
+	interpreter.loop.cancelWhenUnreachable(fiber, fiber.coroutine)
+	return fiber
+}
+
+func (f *LoxFiber) resume(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError) {
+	if f.coroutine.done {
+		return nil, interpreter.nativeError("Can't resume a finished fiber.")
+	}
+
+	if f.coroutine.running {
+		return nil, interpreter.nativeError("Fiber is already running.")
+	}
+
+	step := f.coroutine.transfer(value)
+	return step.value, step.err
+}
+
+func (f *LoxFiber) get(name scanner.Token) (LoxValue, RuntimeError) {
+	switch name.Lexeme {
+	case "resume":
+		return NewLoxCallable(VARIADIC_ARITY, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			if len(args) > 1 {
+				return nil, interpreter.nativeError(fmt.Sprintf("Expected at most 1 argument but got %d.", len(args)))
+			}
+
+			var value LoxValue
+			if len(args) == 1 {
+				value = args[0]
+			}
+			return f.resume(interpreter, value)
+		}), nil
+	case "isDone":
+		return NewLoxCallable(0, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return f.coroutine.done, nil
+		}), nil
+	}
+
+	return nil, NewRuntimeError(name.Line, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+func (f *LoxFiber) ToString() string {
+	return "<fiber>"
+}
+
+// The global Fiber, which creates fibers when called and provides Fiber.yield.
+type LoxFiberClass struct {
+	Stringifyable
+}
+
+func (c *LoxFiberClass) Arity() int {
+	return 1
+}
+
+func (c *LoxFiberClass) Call(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+	function, isCallable := args[0].(Callable)
+	if !isCallable || function.Arity() > 1 {
+		return nil, interpreter.nativeError("Argument to 'Fiber' must be a function taking at most one argument.")
+	}
+
+	// The value passed to the first resume becomes the function's argument.
+	return newLoxFiber(interpreter, interpreter.globals, func(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError) {
+		var args []LoxValue
+		if function.Arity() == 1 {
+			args = append(args, value)
+		}
+		return function.Call(interpreter, args)
+	}), nil
+}
+
+func (c *LoxFiberClass) get(name scanner.Token) (LoxValue, RuntimeError) {
+	if name.Lexeme != "yield" {
+		return nil, NewRuntimeError(name.Line, fmt.Sprintf("Undefined static method '%s'.", name.Lexeme))
+	}
+
+	return NewLoxCallable(VARIADIC_ARITY, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		if len(args) > 1 {
+			return nil, interpreter.nativeError(fmt.Sprintf("Expected at most 1 argument but got %d.", len(args)))
+		}
+
+		if interpreter.fiber == nil || interpreter.fiber.async {
+			return nil, interpreter.nativeError("Can't yield outside of a fiber.")
+		}
+
+		var value LoxValue
+		if len(args) == 1 {
+			value = args[0]
+		}
+		return interpreter.fiber.suspend(value), nil
+	}), nil
+}
+
+func (c *LoxFiberClass) ToString() string {
+	return "Fiber"
+}
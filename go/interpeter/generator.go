@@ -0,0 +1,90 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type LoxGenerator struct {
+	Stringifyable
+	function  *LoxFunction
+	coroutine *coroutine
+
+	buffered bool
+	value    LoxValue
+}
+
+func NewLoxGenerator(interpreter *Interpreter, function *LoxFunction, env *Environment, args []LoxValue) *LoxGenerator {
+	generator := &LoxGenerator{function: function}
+	generator.coroutine = newCoroutine(func(interpreter *Interpreter, value LoxValue) (LoxValue, RuntimeError) {
+		return function.execute(interpreter, env, args)
+	})
+
+	generator.coroutine.interpreter = interpreter.fork("<generator>", env)
+	generator.coroutine.interpreter.generator = generator.coroutine
+	interpreter.loop.cancelWhenUnreachable(generator, generator.coroutine)
+	return generator
+}
+
+// Runs the body until the next yield or until it finishes.
+func (g *LoxGenerator) advance() RuntimeError {
+	if g.coroutine.running {
+		return NewRuntimeError(g.coroutine.interpreter.currentFrame().Line, "Generator is already running.")
+	}
+
+	step := g.coroutine.transfer(nil)
+	if step.err != nil || step.done {
+		return step.err
+	}
+
+	g.buffered = true
+	g.value = step.value
+	return nil
+}
+
+func (g *LoxGenerator) hasNext() (bool, RuntimeError) {
+	if !g.buffered && !g.coroutine.done {
+		if err := g.advance(); err != nil {
+			return false, err
+		}
+	}
+	return g.buffered, nil
+}
+
+func (g *LoxGenerator) next() (LoxValue, RuntimeError) {
+	if _, err := g.hasNext(); err != nil {
+		return nil, err
+	}
+
+	value := g.value
+	g.buffered = false
+	g.value = nil
+	return value, nil
+}
+
+func (g *LoxGenerator) get(name scanner.Token) (LoxValue, RuntimeError) {
+	switch name.Lexeme {
+	case HAS_NEXT_METHOD:
+		return NewLoxCallable(0, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return g.hasNext()
+		}), nil
+	case NEXT_METHOD:
+		return NewLoxCallable(0, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			hasNext, err := g.hasNext()
+			if err != nil {
+				return nil, err
+			}
+
+			if !hasNext {
+				return nil, interpreter.nativeError("Generator is exhausted.")
+			}
+			return g.next()
+		}), nil
+	}
+
+	return nil, NewRuntimeError(name.Line, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+func (g *LoxGenerator) ToString() string {
+	return "<generator " + g.function.declaration.Name.Lexeme + ">"
+}
@@ -0,0 +1,138 @@
+package interpeter
+
+import (
+	"github.com/lukas-reining/lox/parser/statements"
+)
+
+// The outcomes of the places where execution branches.
+type BranchKind string
+
+const (
+	THEN_BRANCH          BranchKind = "then"
+	ELSE_BRANCH          BranchKind = "else"
+	SHORT_CIRCUIT_BRANCH BranchKind = "short-circuit"
+	RIGHT_BRANCH         BranchKind = "right"
+	BODY_BRANCH          BranchKind = "body"
+	// A loop whose body didn't run at all.
+	SKIPPED_BRANCH BranchKind = "skipped"
+)
+
+type Hook interface {
+	BeforeProgram(interpreter *Interpreter, program *Program)
+	BeforeStatement(interpreter *Interpreter, statement statements.Statement[LoxValue, RuntimeError]) RuntimeError
+	EnterFunction(interpreter *Interpreter, frame *CallFrame)
+	ExitFunction(interpreter *Interpreter, frame *CallFrame, value LoxValue, err RuntimeError)
+	// Called with the branch taken by an If, a Conditional or a Logical, and for each loop iteration.
+	Branch(interpreter *Interpreter, node any, branch BranchKind)
+	// Called whenever a variable is declared, including parameters, and whenever one is assigned.
+	DefineVariable(interpreter *Interpreter, name string, value LoxValue)
+	AssignVariable(interpreter *Interpreter, name string, value LoxValue)
+}
+
+type BaseHook struct {
+	Hook
+}
+
+func (h *BaseHook) BeforeProgram(interpreter *Interpreter, program *Program) {
+}
+
+func (h *BaseHook) BeforeStatement(interpreter *Interpreter, statement statements.Statement[LoxValue, RuntimeError]) RuntimeError {
+	return nil
+}
+
+func (h *BaseHook) EnterFunction(interpreter *Interpreter, frame *CallFrame) {
+}
+
+func (h *BaseHook) ExitFunction(interpreter *Interpreter, frame *CallFrame, value LoxValue, err RuntimeError) {
+}
+
+func (h *BaseHook) Branch(interpreter *Interpreter, node any, branch BranchKind) {
+}
+
+func (h *BaseHook) DefineVariable(interpreter *Interpreter, name string, value LoxValue) {
+}
+
+func (h *BaseHook) AssignVariable(interpreter *Interpreter, name string, value LoxValue) {
+}
+
+type CallFrame struct {
+	Name      string
+	Line      int
+	Arguments []LoxValue
+
+	// The environment the frame was executing in when it called into the next frame.
+	env *Environment
+}
+
+func (i *Interpreter) AddHook(hook Hook) {
+	i.hooks = append(i.hooks, hook)
+
+	// Environments created from now on inherit the watcher from the globals.
+	if i.globals.watcher == nil {
+		i.globals.watcher = i.variableChanged
+	}
+}
+
+func (i *Interpreter) Frames() []*CallFrame {
+	return i.frames
+}
+
+func (i *Interpreter) Globals() *Environment {
+	return i.globals
+}
+
+func (i *Interpreter) FrameEnvironment(index int) *Environment {
+	if index == len(i.frames)-1 {
+		return i.env
+	}
+
+	return i.frames[index].env
+}
+
+func (i *Interpreter) currentFrame() *CallFrame {
+	return i.frames[len(i.frames)-1]
+}
+
+func (i *Interpreter) enterFunction(name string, line int, args []LoxValue) {
+	i.currentFrame().env = i.env
+
+	frame := &CallFrame{Name: name, Line: line, Arguments: args}
+	i.frames = append(i.frames, frame)
+
+	for _, hook := range i.hooks {
+		hook.EnterFunction(i, frame)
+	}
+}
+
+func (i *Interpreter) exitFunction(value LoxValue, err RuntimeError) {
+	frame := i.currentFrame()
+
+	for _, hook := range i.hooks {
+		hook.ExitFunction(i, frame, value, err)
+	}
+
+	i.frames = i.frames[:len(i.frames)-1]
+}
+
+func (i *Interpreter) branch(node any, branch BranchKind) {
+	for _, hook := range i.hooks {
+		hook.Branch(i, node, branch)
+	}
+}
+
+// The watcher belongs to the main interpreter, variables changed by coroutines are reported with
+// the coroutine's interpreter.
+func (i *Interpreter) variableChanged(name string, value LoxValue, defined bool) {
+	interpreter := i
+	if i.loop.running != nil {
+		interpreter = i.loop.running
+	}
+
+	for _, hook := range interpreter.hooks {
+		if defined {
+			hook.DefineVariable(interpreter, name, value)
+		} else {
+			hook.AssignVariable(interpreter, name, value)
+		}
+	}
+}
@@ -5,6 +5,11 @@ import (
 	"github.com/lukas-reining/lox/parser/expressions"
 	"github.com/lukas-reining/lox/parser/statements"
 	"github.com/lukas-reining/lox/scanner"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"strconv"
 	"time"
 )
 
@@ -15,6 +20,16 @@ type Interpreter struct {
 	env     *Environment
 	globals *Environment
 	locals  map[expressions.Expression[LoxValue, RuntimeError]]int
+	hooks   []Hook
+	frames  []*CallFrame
+	out     io.Writer
+
+	loop *eventLoop
+
+	// The coroutines of the generator and the fiber whose body this interpreter is running, if
+	// any. Not the generator and fiber themselves, those must stay collectable while suspended.
+	generator *coroutine
+	fiber     *coroutine
 }
 
 func NewInterpreterWithEnv(env *Environment) Interpreter {
@@ -25,6 +40,9 @@ func NewInterpreterWithEnv(env *Environment) Interpreter {
 		globals: newEnv,
 		env:     newEnv,
 		locals:  map[expressions.Expression[LoxValue, RuntimeError]]int{},
+		frames:  []*CallFrame{{Name: "<script>"}},
+		out:     os.Stdout,
+		loop:    newEventLoop(),
 	}
 }
 
@@ -40,9 +58,16 @@ func defineGlobals(globals *Environment) {
 	}))
 
 	globals.define("printStackDepth", NewLoxCallable(0, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
-		fmt.Printf("Stack Depth: %d\n", interpreter.env.level)
+		_, _ = fmt.Fprintf(interpreter.out, "Stack Depth: %d\n", interpreter.env.level)
 		return nil, nil
 	}))
+
+	defineStringGlobals(globals)
+	defineListGlobals(globals)
+	defineMapGlobals(globals)
+	defineInstanceGlobals(globals)
+	defineAsyncGlobals(globals)
+	defineAssertGlobals(globals)
 }
 
 func GetGlobalEnv() *Environment {
@@ -71,8 +96,10 @@ func Stringify(value LoxValue) string {
 		switch v := value.(type) {
 		case string:
 			return fmt.Sprint(value)
+		case int64:
+			return strconv.FormatInt(v, 10)
 		case float64:
-			return fmt.Sprint(value)
+			return formatFloat(v)
 		case bool:
 			return fmt.Sprint(value)
 		case Stringifyable:
@@ -87,6 +114,23 @@ func createBinaryNumberOperatorError(operator scanner.Token) RuntimeError {
 	return NewRuntimeError(operator.Line, "Operands must be numbers.")
 }
 
+func checkedIntResult(operator scanner.Token) func(int64, bool) (LoxValue, RuntimeError) {
+	return func(result int64, ok bool) (LoxValue, RuntimeError) {
+		if !ok {
+			return nil, NewRuntimeError(operator.Line, "Integer overflow.")
+		}
+		return result, nil
+	}
+}
+
+func createDivisionByZeroError(operator scanner.Token) RuntimeError {
+	return NewRuntimeError(operator.Line, "Division by zero.")
+}
+
+func createBinaryIntegerOperatorError(operator scanner.Token) RuntimeError {
+	return NewRuntimeError(operator.Line, "Operands must be integers.")
+}
+
 func createBinaryNumberOrStringOperatorError(operator scanner.Token) RuntimeError {
 	return NewRuntimeError(operator.Line, "Operands must be two numbers or two strings.")
 }
@@ -106,66 +150,158 @@ func (i *Interpreter) VisitBinaryExpression(exp *expressions.Binary[LoxValue, Ru
 		return nil, err
 	}
 
-	leftNumber, leftNumberOk := left.(float64)
-	rightNumber, rightNumberOk := right.(float64)
+	return i.binary(exp.Operator, left, right)
+}
+
+func (i *Interpreter) binary(operator scanner.Token, left LoxValue, right LoxValue) (LoxValue, RuntimeError) {
+	if value, handled, err := i.binaryMethod(operator, left, right); handled {
+		return value, err
+	}
+
+	leftInt, rightInt, intsOk := toInts(left, right)
+	leftNumber, rightNumber, numbersOk := toFloats(left, right)
 
 	leftString, leftStringOk := left.(string)
 	rightString, rightStringOk := right.(string)
 
-	switch exp.Operator.Type {
+	switch operator.Type {
 	case scanner.MINUS:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return checkedIntResult(operator)(subtractInts(leftInt, rightInt))
+		}
+		if numbersOk {
 			return leftNumber - rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
 	case scanner.PLUS:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return checkedIntResult(operator)(addInts(leftInt, rightInt))
+		}
+		if numbersOk {
 			return leftNumber + rightNumber, nil
 		}
 		if leftStringOk && rightStringOk {
 			return leftString + rightString, nil
 		}
-		if leftNumberOk && rightStringOk {
-			return Stringify(leftNumber) + rightString, nil
+		if isNumber(left) && rightStringOk {
+			return Stringify(left) + rightString, nil
+		}
+		if leftStringOk && isNumber(right) {
+			return leftString + Stringify(right), nil
+		}
+		return nil, createBinaryNumberOrStringOperatorError(operator)
+	case scanner.INTERPOLATION:
+		leftText, err := i.stringify(left)
+		if err != nil {
+			return nil, err
 		}
-		if leftStringOk && rightNumberOk {
-			return leftString + Stringify(rightNumber), nil
+
+		rightText, err := i.stringify(right)
+		if err != nil {
+			return nil, err
 		}
-		return nil, createBinaryNumberOrStringOperatorError(exp.Operator)
+
+		return leftText + rightText, nil
 	case scanner.STAR:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return checkedIntResult(operator)(multiplyInts(leftInt, rightInt))
+		}
+		if numbersOk {
 			return leftNumber * rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
 	case scanner.SLASH:
-		if leftNumberOk && rightNumberOk {
+		if numbersOk && rightNumber == 0 {
+			return nil, createDivisionByZeroError(operator)
+		}
+		if intsOk {
+			return divideInts(leftInt, rightInt), nil
+		}
+		if numbersOk {
 			return leftNumber / rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
+	case scanner.PERCENT:
+		if numbersOk && rightNumber == 0 {
+			return nil, createDivisionByZeroError(operator)
+		}
+		if intsOk {
+			return leftInt % rightInt, nil
+		}
+		if numbersOk {
+			return math.Mod(leftNumber, rightNumber), nil
+		}
+		return nil, createBinaryNumberOperatorError(operator)
+	case scanner.STAR_STAR:
+		if intsOk && rightInt >= 0 {
+			return checkedIntResult(operator)(powerInts(leftInt, rightInt))
+		}
+		if numbersOk {
+			return math.Pow(leftNumber, rightNumber), nil
+		}
+		return nil, createBinaryNumberOperatorError(operator)
+	case scanner.AMPERSAND:
+		if intsOk {
+			return leftInt & rightInt, nil
+		}
+		return nil, createBinaryIntegerOperatorError(operator)
+	case scanner.PIPE:
+		if intsOk {
+			return leftInt | rightInt, nil
+		}
+		return nil, createBinaryIntegerOperatorError(operator)
+	case scanner.CARET:
+		if intsOk {
+			return leftInt ^ rightInt, nil
+		}
+		return nil, createBinaryIntegerOperatorError(operator)
+	case scanner.LESS_LESS, scanner.GREATER_GREATER:
+		if !intsOk {
+			return nil, createBinaryIntegerOperatorError(operator)
+		}
+		if rightInt < 0 {
+			return nil, NewRuntimeError(operator.Line, "Shift count must not be negative.")
+		}
+		if operator.Type == scanner.LESS_LESS {
+			return leftInt << rightInt, nil
+		}
+		return leftInt >> rightInt, nil
 	case scanner.GREATER:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return leftInt > rightInt, nil
+		}
+		if numbersOk {
 			return leftNumber > rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
 	case scanner.GREATER_EQUAL:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return leftInt >= rightInt, nil
+		}
+		if numbersOk {
 			return leftNumber >= rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
 	case scanner.LESS:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return leftInt < rightInt, nil
+		}
+		if numbersOk {
 			return leftNumber < rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
 	case scanner.LESS_EQUAL:
-		if leftNumberOk && rightNumberOk {
+		if intsOk {
+			return leftInt <= rightInt, nil
+		}
+		if numbersOk {
 			return leftNumber <= rightNumber, nil
 		}
-		return nil, createBinaryNumberOperatorError(exp.Operator)
+		return nil, createBinaryNumberOperatorError(operator)
 	case scanner.EQUAL_EQUAL:
-		return left == right, nil
+		return isEqual(left, right), nil
 	case scanner.BANG_EQUAL:
-		return left != right, nil
+		return !isEqual(left, right), nil
 	}
 
 	// Unreachable.
@@ -181,9 +317,24 @@ func (i *Interpreter) VisitUnaryExpression(exp *expressions.Unary[LoxValue, Runt
 
 	switch exp.Operator.Type {
 	case scanner.MINUS:
-		if number, ok := right.(float64); ok {
+		if method, hasMethod := protocolMethod(right, NEGATE_METHOD); hasMethod {
+			return i.callMethod(method, exp.Operator.Line)
+		}
+
+		switch number := right.(type) {
+		case int64:
+			if number == math.MinInt64 {
+				return nil, NewRuntimeError(exp.Operator.Line, "Integer overflow.")
+			}
+			return -number, nil
+		case float64:
 			return -number, nil
 		}
+	case scanner.TILDE:
+		if number, ok := right.(int64); ok {
+			return ^number, nil
+		}
+		return nil, NewRuntimeError(exp.Operator.Line, "Operand must be an integer.")
 	case scanner.BANG:
 		return isTruthy(right), nil
 	}
@@ -202,50 +353,367 @@ func (i *Interpreter) VisitExpressionStatement(statement *statements.Expression[
 
 func (i *Interpreter) VisitPrintStatement(statement *statements.Print[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	value, err := i.evaluate(statement.Exp)
+	if err != nil {
+		return nil, err
+	}
 
+	text, err := i.stringify(value)
 	if err == nil {
-		println(Stringify(value))
+		_, _ = fmt.Fprintln(i.out, text)
 	}
 
 	return nil, err
 }
 
 func (i *Interpreter) VisitVarStatement(statement *statements.Var[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	var value LoxValue
 	if statement.Initializer != nil {
-		value, err := i.evaluate(statement.Initializer)
+		var err RuntimeError
+		if value, err = i.evaluate(statement.Initializer); err != nil {
+			return nil, err
+		}
+	}
 
+	return nil, i.defineVariable(statement.Name, value, statement.Constant)
+}
+
+func (i *Interpreter) VisitForInStatement(statement *statements.ForIn[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	iterable, err := i.evaluate(statement.Iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := i.iterator(statement.Keyword, iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	for first := true; ; first = false {
+		hasNext, err := elements.hasNext()
+		if err == nil && !hasNext && first {
+			i.branch(statement, SKIPPED_BRANCH)
+		}
+		if err != nil || !hasNext {
+			return nil, err
+		}
+
+		element, err := elements.next()
 		if err != nil {
 			return nil, err
 		}
 
-		i.env.define(statement.Name.Lexeme, value)
-	} else {
-		i.env.define(statement.Name.Lexeme, nil)
+		i.branch(statement, BODY_BRANCH)
+		env := NewEnvironment(i.env)
+		env.define(statement.Name.Lexeme, element)
+		value, err := i.executeBlock([]statements.Statement[LoxValue, RuntimeError]{statement.Body}, env)
+		if _, isReturn := value.(*ReturnValue); isReturn || err != nil {
+			return value, err
+		}
+	}
+}
+
+func (i *Interpreter) VisitAwaitExpression(exp *expressions.Await[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	value, err := i.evaluate(exp.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if promise, isPromise := value.(*LoxPromise); isPromise {
+		return i.await(promise)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitRangeExpression(exp *expressions.Range[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	start, err := i.evaluate(exp.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := i.evaluate(exp.End)
+	if err != nil {
+		return nil, err
+	}
+
+	startInt, isStartInt := start.(int64)
+	endInt, isEndInt := end.(int64)
+	if !isStartInt || !isEndInt {
+		return nil, NewRuntimeError(exp.Operator.Line, "Range bounds must be integers.")
+	}
+
+	return NewLoxRange(startInt, endInt), nil
+}
+
+func (i *Interpreter) VisitYieldStatement(statement *statements.Yield[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	var value LoxValue
+	if statement.Value != nil {
+		var err RuntimeError
+		if value, err = i.evaluate(statement.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.generator == nil {
+		return nil, NewRuntimeError(statement.Keyword.Line, "Can't yield outside of a generator.")
+	}
+
+	i.generator.suspend(value)
+	return nil, nil
+}
+
+func (i *Interpreter) VisitMatchStatement(statement *statements.Match[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	value, err := i.evaluate(statement.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, matchCase := range statement.Cases {
+		bindings := map[string]LoxValue{}
+		matched, err := i.matchPattern(matchCase.Pattern, value, bindings)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			env := NewEnvironment(i.env)
+			for name, binding := range bindings {
+				env.define(name, binding)
+			}
+			return i.executeBlock([]statements.Statement[LoxValue, RuntimeError]{matchCase.Body}, env)
+		}
+	}
+
+	return nil, nil
+}
+
+func (i *Interpreter) matchPattern(pattern *statements.CasePattern[LoxValue, RuntimeError], value LoxValue, bindings map[string]LoxValue) (bool, RuntimeError) {
+	switch pattern.Kind {
+	case statements.LITERAL_CASE:
+		return isEqual(pattern.Value, value), nil
+	case statements.WILDCARD_CASE:
+		return true, nil
+	case statements.BINDING_CASE:
+		bindings[pattern.Token.Lexeme] = value
+		return true, nil
+	case statements.ALTERNATIVE_CASE:
+		for _, alternative := range pattern.Patterns {
+			if matched, err := i.matchPattern(alternative, value, bindings); err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	}
+
+	classValue, err := i.evaluate(pattern.Class)
+	if err != nil {
+		return false, err
+	}
+
+	class, isClass := classValue.(*LoxClass)
+	if !isClass {
+		return false, NewRuntimeError(pattern.Token.Line, fmt.Sprintf("'%s' is not a class.", pattern.Token.Lexeme))
+	}
+
+	instance, isInstance := value.(*LoxInstance)
+	if !isInstance || instance.Class != class {
+		return false, nil
+	}
+
+	// Class patterns match positionally against the fields named by the init parameters.
+	var params []scanner.Token
+	if initializer, hasInit := class.Methods["init"]; hasInit {
+		params = initializer.declaration.Params
+	}
+
+	if len(pattern.Patterns) > len(params) {
+		return false, NewRuntimeError(pattern.Token.Line, fmt.Sprintf("Class pattern for '%s' expects at most %d fields but got %d.", class.Name, len(params), len(pattern.Patterns)))
+	}
+
+	for index, subPattern := range pattern.Patterns {
+		field, err := instance.get(i, params[index])
+		if err != nil {
+			return false, err
+		}
+
+		if matched, err := i.matchPattern(subPattern, field, bindings); err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (i *Interpreter) VisitDestructureStatement(statement *statements.Destructure[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	value, err := i.evaluate(statement.Initializer)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := i.destructure(statement.Pattern, value)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, name := range statement.Pattern.Names {
+		if err := i.defineVariable(name, values[index], statement.Constant); err != nil {
+			return nil, err
+		}
 	}
 	return nil, nil
 }
 
+func (i *Interpreter) destructure(pattern *statements.Pattern, value LoxValue) ([]LoxValue, RuntimeError) {
+	if pattern.Kind == statements.OBJECT_PATTERN {
+		instance, isInstance := value.(*LoxInstance)
+		if !isInstance {
+			return nil, NewRuntimeError(pattern.Brace.Line, "Only instances can be destructured with an object pattern.")
+		}
+
+		var values []LoxValue
+		for _, name := range pattern.Names {
+			property, err := instance.get(i, name)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, property)
+		}
+		return values, nil
+	}
+
+	list, isList := value.(*LoxList)
+	if !isList {
+		return nil, NewRuntimeError(pattern.Brace.Line, "Only lists can be destructured with a list pattern.")
+	}
+
+	if len(list.Elements) != len(pattern.Names) {
+		return nil, unpackError(pattern.Brace.Line, len(pattern.Names), len(list.Elements))
+	}
+	return list.Elements, nil
+}
+
+func unpackError(line int, expected int, actual int) RuntimeError {
+	return NewRuntimeError(line, fmt.Sprintf("Expected %d values to unpack but got %d.", expected, actual))
+}
+
+func (i *Interpreter) defineVariable(name scanner.Token, value LoxValue, constant bool) RuntimeError {
+	if i.env.isConstant(name.Lexeme) {
+		return NewRuntimeError(name.Line, "Can't redeclare constant '"+name.Lexeme+"'.")
+	}
+
+	if constant {
+		i.env.defineConstant(name.Lexeme, value)
+	} else {
+		i.env.define(name.Lexeme, value)
+	}
+	return nil
+}
+
 func (i *Interpreter) VisitVariableExpression(exp *expressions.Variable[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	return i.lookupVariable(exp.Name, exp)
 }
 
+var compoundOperators = map[scanner.TokenType]scanner.TokenType{
+	scanner.PLUS_EQUAL:  scanner.PLUS,
+	scanner.MINUS_EQUAL: scanner.MINUS,
+	scanner.STAR_EQUAL:  scanner.STAR,
+	scanner.SLASH_EQUAL: scanner.SLASH,
+}
+
+// Combines the current value of an assignment target with the assigned value for "+=" and friends.
+func (i *Interpreter) compound(operator scanner.Token, current func() (LoxValue, RuntimeError), value LoxValue) (LoxValue, RuntimeError) {
+	binaryType, isCompound := compoundOperators[operator.Type]
+	if !isCompound {
+		return value, nil
+	}
+
+	currentValue, err := current()
+	if err != nil {
+		return nil, err
+	}
+
+	binaryOperator := scanner.NewToken(binaryType, operator.Lexeme[:len(operator.Lexeme)-1], nil, operator.Line, operator.Column)
+	return i.binary(binaryOperator, currentValue, value)
+}
+
 func (i *Interpreter) VisitAssignmentExpression(exp *expressions.Assignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	value, err := i.evaluate(exp.Value)
 	if err != nil {
 		return nil, err
 	}
 
-	distance, hasDistance := i.locals[exp]
+	value, err = i.compound(exp.Operator, func() (LoxValue, RuntimeError) {
+		return i.lookupVariable(exp.Name, exp)
+	}, value)
+	if err != nil {
+		return nil, err
+	}
 
-	if hasDistance {
-		i.env.assignAt(distance, exp.Name, value)
-	} else {
-		if err := i.globals.assign(exp.Name, value); err != nil {
+	return value, i.assignVariable(exp.Name, exp, value)
+}
+
+func (i *Interpreter) assignVariable(name scanner.Token, exp expressions.Expression[LoxValue, RuntimeError], value LoxValue) RuntimeError {
+	if distance, hasDistance := i.locals[exp]; hasDistance {
+		return i.env.assignAt(distance, name, value)
+	}
+
+	return i.globals.assign(name, value)
+}
+
+func (i *Interpreter) VisitMultiAssignmentExpression(exp *expressions.MultiAssignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	var values []LoxValue
+	for _, expression := range exp.Values {
+		value, err := i.evaluate(expression)
+		if err != nil {
 			return nil, err
 		}
+		values = append(values, value)
 	}
 
-	return value, nil
+	if list, isList := values[0].(*LoxList); isList && len(values) == 1 && len(exp.Targets) > 1 {
+		values = append([]LoxValue{}, list.Elements...)
+	}
+
+	if len(values) != len(exp.Targets) {
+		return nil, unpackError(exp.Equals.Line, len(exp.Targets), len(values))
+	}
+
+	for index, target := range exp.Targets {
+		if err := i.assignTarget(target, values[index]); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (i *Interpreter) assignTarget(target expressions.Expression[LoxValue, RuntimeError], value LoxValue) RuntimeError {
+	switch t := target.(type) {
+	case *expressions.Variable[LoxValue, RuntimeError]:
+		return i.assignVariable(t.Name, t, value)
+	case *expressions.Get[LoxValue, RuntimeError]:
+		object, err := i.evaluate(t.Object)
+		if err != nil {
+			return err
+		}
+
+		instance, isInstance := object.(*LoxInstance)
+		if !isInstance {
+			return NewRuntimeError(t.Name.Line, "Only instances have Fields.")
+		}
+		return instance.set(i, t.Name, value)
+	case *expressions.Index[LoxValue, RuntimeError]:
+		object, err := i.evaluate(t.Object)
+		if err != nil {
+			return err
+		}
+
+		index, err := i.evaluate(t.Index)
+		if err != nil {
+			return err
+		}
+		return i.setIndex(t.Bracket, object, index, value)
+	}
+
+	return nil
 }
 
 func (i *Interpreter) VisitBlockStatement(statement *statements.Block[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
@@ -264,9 +732,13 @@ func (i *Interpreter) VisitIfStatement(statement *statements.If[LoxValue, Runtim
 	var value LoxValue
 	var evalErr RuntimeError
 	if isTruthy(condition) && statement.IfBranch != nil {
+		i.branch(statement, THEN_BRANCH)
 		value, evalErr = i.execute(statement.IfBranch)
-	} else if statement.ElseBranch != nil {
-		value, evalErr = i.execute(statement.ElseBranch)
+	} else {
+		i.branch(statement, ELSE_BRANCH)
+		if statement.ElseBranch != nil {
+			value, evalErr = i.execute(statement.ElseBranch)
+		}
 	}
 	return value, evalErr
 }
@@ -278,25 +750,68 @@ func (i *Interpreter) VisitLogicalExpression(expr *expressions.Logical[LoxValue,
 		return nil, err
 	}
 
-	if expr.Operator.Type == scanner.OR && isTruthy(left) {
-		return left, nil
-	} else if expr.Operator.Type != scanner.OR && !isTruthy(left) {
+	var shortCircuits bool
+	switch expr.Operator.Type {
+	case scanner.OR:
+		shortCircuits = isTruthy(left)
+	case scanner.QUESTION_QUESTION:
+		shortCircuits = left != nil
+	default:
+		shortCircuits = !isTruthy(left)
+	}
+
+	if shortCircuits {
+		i.branch(expr, SHORT_CIRCUIT_BRANCH)
 		return left, nil
 	}
 
+	i.branch(expr, RIGHT_BRANCH)
 	return i.evaluate(expr.Right)
 }
 
+func (i *Interpreter) VisitConditionalExpression(exp *expressions.Conditional[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	condition, err := i.evaluate(exp.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTruthy(condition) {
+		i.branch(exp, THEN_BRANCH)
+		return i.evaluate(exp.ThenBranch)
+	}
+	i.branch(exp, ELSE_BRANCH)
+	return i.evaluate(exp.ElseBranch)
+}
+
+// Returned by an optional property access on nil and caught by the enclosing optional chain.
+var shortCircuit RuntimeError = NewRuntimeError(0, "Optional chain short-circuited.")
+
+func (i *Interpreter) VisitOptionalChainExpression(exp *expressions.OptionalChain[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	value, err := i.evaluate(exp.Chain)
+	if err == shortCircuit {
+		return nil, nil
+	}
+	return value, err
+}
+
 func (i *Interpreter) VisitWhileStatement(statement *statements.While[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	condition, err := i.evaluate(statement.Condition)
+	if err == nil && !isTruthy(condition) {
+		i.branch(statement, SKIPPED_BRANCH)
+	}
 
 	for err == nil && isTruthy(condition) {
-		_, bodyError := i.execute(statement.Body)
+		i.branch(statement, BODY_BRANCH)
+		value, bodyError := i.execute(statement.Body)
 
 		if bodyError != nil {
 			return nil, bodyError
 		}
 
+		if _, isReturn := value.(*ReturnValue); isReturn {
+			return value, nil
+		}
+
 		condition, err = i.evaluate(statement.Condition)
 	}
 
@@ -318,12 +833,16 @@ func (i *Interpreter) VisitCallExpression(exp *expressions.Call[LoxValue, Runtim
 		}
 	}
 
+	if method, hasMethod := protocolMethod(callee, CALL_METHOD); hasMethod {
+		callee = method
+	}
+
 	callable, isCallable := callee.(Callable)
 	if !isCallable {
 		return nil, NewRuntimeError(exp.Parenthesis.Line, "Can only call functions and classes.")
 	}
 
-	if len(args) != callable.Arity() {
+	if arity := callable.Arity(); arity != VARIADIC_ARITY && len(args) != arity {
 		return nil, NewRuntimeError(exp.Parenthesis.Line, fmt.Sprintf("Expected %d arguments but got %d.", callable.Arity(), len(args)))
 	}
 
@@ -336,13 +855,21 @@ func (i *Interpreter) VisitGetExpression(exp *expressions.Get[LoxValue, RuntimeE
 		return nil, err
 	}
 
+	if exp.Optional && object == nil {
+		return nil, shortCircuit
+	}
+
 	switch o := object.(type) {
 	case *LoxInstance:
-		value, err := o.get(exp.Name)
+		value, err := o.get(i, exp.Name)
 		return value, err
 	case LoxInstance:
-		value, err := o.get(exp.Name)
+		value, err := o.get(i, exp.Name)
 		return value, err
+	case *LoxClass:
+		return o.get(exp.Name)
+	case nativeObject:
+		return o.get(exp.Name)
 	}
 
 	return nil, NewRuntimeError(exp.Name.Line, "Only instances have properties.")
@@ -361,24 +888,137 @@ func (i *Interpreter) VisitSetExpression(exp *expressions.Set[LoxValue, RuntimeE
 
 	switch o := object.(type) {
 	case *LoxInstance:
-		o.set(exp.Name, value)
-		return nil, nil
+		value, err = i.compound(exp.Operator, func() (LoxValue, RuntimeError) { return o.get(i, exp.Name) }, value)
+		if err != nil {
+			return nil, err
+		}
+		return nil, o.set(i, exp.Name, value)
 	case LoxInstance:
-		o.set(exp.Name, value)
-		return nil, nil
+		value, err = i.compound(exp.Operator, func() (LoxValue, RuntimeError) { return o.get(i, exp.Name) }, value)
+		if err != nil {
+			return nil, err
+		}
+		return nil, o.set(i, exp.Name, value)
 	}
 
 	return nil, NewRuntimeError(exp.Name.Line, "Only instances have Fields.")
 }
 
+func (i *Interpreter) VisitListExpression(exp *expressions.List[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	elements := []LoxValue{}
+	for _, element := range exp.Elements {
+		value, err := i.evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, value)
+	}
+
+	return NewLoxList(elements), nil
+}
+
+func (i *Interpreter) VisitIndexExpression(exp *expressions.Index[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	object, err := i.evaluate(exp.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := i.evaluate(exp.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.index(exp.Bracket, object, index)
+}
+
+func (i *Interpreter) index(bracket scanner.Token, object LoxValue, index LoxValue) (LoxValue, RuntimeError) {
+	switch o := object.(type) {
+	case *LoxList:
+		position, err := elementIndex(bracket, index, len(o.Elements))
+		if err != nil {
+			return nil, err
+		}
+		return o.Elements[position], nil
+	case string:
+		characters := []rune(o)
+		position, err := elementIndex(bracket, index, len(characters))
+		if err != nil {
+			return nil, err
+		}
+		return string(characters[position]), nil
+	case *LoxMap:
+		return o.lookup(index), nil
+	}
+
+	if method, hasMethod := protocolMethod(object, GET_METHOD); hasMethod {
+		return i.callMethod(method, bracket.Line, index)
+	}
+
+	return nil, NewRuntimeError(bracket.Line, "Only lists, strings and maps can be indexed.")
+}
+
+func (i *Interpreter) VisitSetIndexExpression(exp *expressions.SetIndex[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	object, err := i.evaluate(exp.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := i.evaluate(exp.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := i.evaluate(exp.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = i.compound(exp.Operator, func() (LoxValue, RuntimeError) { return i.index(exp.Bracket, object, index) }, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, i.setIndex(exp.Bracket, object, index, value)
+}
+
+func (i *Interpreter) setIndex(bracket scanner.Token, object LoxValue, index LoxValue, value LoxValue) RuntimeError {
+	if method, hasMethod := protocolMethod(object, SET_METHOD); hasMethod {
+		_, err := i.callMethod(method, bracket.Line, index, value)
+		return err
+	}
+
+	if dictionary, isMap := object.(*LoxMap); isMap {
+		return dictionary.set(bracket.Line, index, value)
+	}
+
+	list, isList := object.(*LoxList)
+	if !isList {
+		return NewRuntimeError(bracket.Line, "Only list elements and map entries can be assigned.")
+	}
+
+	position, err := elementIndex(bracket, index, len(list.Elements))
+	if err != nil {
+		return err
+	}
+
+	list.Elements[position] = value
+	return nil
+}
+
+func (i *Interpreter) VisitLambdaExpression(exp *expressions.Lambda[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	function := exp.Function.(*statements.Function[LoxValue, RuntimeError])
+	return NewLoxFunction(*function, i.env, false), nil
+}
+
 func (i *Interpreter) VisitFunctionStatement(statement *statements.Function[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	function := NewLoxFunction(*statement, i.env, false)
-	i.env.define(function.declaration.Name.Lexeme, function)
-	return nil, nil
+	return nil, i.defineVariable(statement.Name, function, false)
 }
 
 func (i *Interpreter) VisitClassStatement(statement *statements.Class[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
-	i.env.define(statement.Name.Lexeme, nil)
+	if err := i.defineVariable(statement.Name, nil, false); err != nil {
+		return nil, err
+	}
 
 	methods := map[string]LoxFunction{}
 	for _, method := range statement.Methods {
@@ -386,10 +1026,50 @@ func (i *Interpreter) VisitClassStatement(statement *statements.Class[LoxValue,
 		methods[method.Name.Lexeme] = fn
 	}
 
+	composition := newTraitComposition(statement)
+	for _, variable := range statement.Traits {
+		value, err := i.evaluate(variable)
+		if err != nil {
+			return nil, err
+		}
+
+		trait, isTrait := value.(*LoxTrait)
+		if !isTrait {
+			return nil, NewRuntimeError(variable.Name.Line, fmt.Sprintf("'%s' is not a trait.", variable.Name.Lexeme))
+		}
+
+		taken, err := composition.add(variable.Name.Line, trait, trait.Name, trait.methodNames())
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range taken {
+			methods[name] = trait.Methods[name]
+		}
+	}
+
 	class := NewLoxClass(statement.Name.Lexeme, methods)
+	for _, method := range statement.StaticMethods {
+		class.StaticMethods[method.Name.Lexeme] = *NewLoxFunction(method, i.env, false)
+	}
+	for _, getter := range statement.Getters {
+		class.Getters[getter.Name.Lexeme] = *NewLoxFunction(getter, i.env, false)
+	}
+	for _, setter := range statement.Setters {
+		class.Setters[setter.Name.Lexeme] = *NewLoxFunction(setter, i.env, false)
+	}
+
 	return nil, i.env.assign(statement.Name, class)
 }
 
+func (i *Interpreter) VisitTraitStatement(statement *statements.Trait[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	methods := map[string]LoxFunction{}
+	for _, method := range statement.Methods {
+		methods[method.Name.Lexeme] = *NewLoxFunction(method, i.env, method.Name.Lexeme == "init")
+	}
+
+	return nil, i.defineVariable(statement.Name, NewLoxTrait(statement.Name.Lexeme, methods), false)
+}
+
 func (i *Interpreter) VisitReturnStatement(statement *statements.Return[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	if statement.Value == nil {
 		return NewReturnValue(nil), nil
@@ -428,13 +1108,10 @@ func (i *Interpreter) executeBlock(statements []statements.Statement[LoxValue, R
 			return nil, err
 		}
 
-		switch returnValue := value.(type) {
-		case ReturnValue:
-			i.env = previousEnv
-			return returnValue.Value, nil
-		case *ReturnValue:
+		// Returns propagate through nested blocks up to the enclosing function call.
+		if returnValue, isReturn := value.(*ReturnValue); isReturn {
 			i.env = previousEnv
-			return returnValue.Value, nil
+			return returnValue, nil
 		}
 	}
 
@@ -446,14 +1123,55 @@ func (i *Interpreter) evaluate(exp expressions.Expression[LoxValue, RuntimeError
 	return exp.Accept(i)
 }
 
-func (i *Interpreter) resolve(exp expressions.Expression[LoxValue, RuntimeError], depth int) {
-	i.locals[exp] = depth
-}
-
 func (i *Interpreter) execute(statement statements.Statement[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	i.currentFrame().Line = statement.Line()
+
+	for _, hook := range i.hooks {
+		if err := hook.BeforeStatement(i, statement); err != nil {
+			return nil, err
+		}
+	}
+
 	return statement.Accept(i)
 }
 
+func (i *Interpreter) EvaluateInFrame(exp expressions.Expression[LoxValue, RuntimeError], frame int) (LoxValue, RuntimeError) {
+	env := i.FrameEnvironment(frame)
+
+	// The program's resolution results may be shared with other interpreters, so the
+	// expression is resolved into a copy.
+	locals := maps.Clone(i.locals)
+	resolver := newResolver(locals, nil)
+	for scope := env; scope != nil && scope != i.globals; scope = scope.enclosing {
+		names := map[string]bool{}
+		for name := range scope.values {
+			names[name] = true
+		}
+		resolver.scopes = append([]map[string]bool{names}, resolver.scopes...)
+		resolver.constants = append([]map[string]bool{scope.constants}, resolver.constants...)
+		resolver.traits = append([]map[string]*statements.Trait[LoxValue, RuntimeError]{{}}, resolver.traits...)
+
+		if names["this"] {
+			resolver.currentClassType = CLASS
+		}
+	}
+
+	if resolver.hasScopes() {
+		resolver.currentFunctionType = FUNCTION
+	}
+
+	if err := resolver.resolveExpression(exp); err != nil {
+		return nil, err
+	}
+
+	previousEnv, previousLocals := i.env, i.locals
+	i.env, i.locals = env, locals
+	value, err := i.evaluate(exp)
+	i.env, i.locals = previousEnv, previousLocals
+
+	return value, err
+}
+
 func (i *Interpreter) Interpret(statements []statements.Statement[LoxValue, RuntimeError]) (LoxValue, *Environment, RuntimeError) {
 	var lastValue LoxValue
 
@@ -466,5 +1184,9 @@ func (i *Interpreter) Interpret(statements []statements.Statement[LoxValue, Runt
 		}
 	}
 
+	if err := i.loop.run(); err != nil {
+		return nil, i.env, err
+	}
+
 	return lastValue, i.env, nil
 }
@@ -0,0 +1,109 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type iterator interface {
+	hasNext() (bool, RuntimeError)
+	next() (LoxValue, RuntimeError)
+}
+
+type listIterator struct {
+	list  *LoxList
+	index int
+}
+
+func (l *listIterator) hasNext() (bool, RuntimeError) {
+	return l.index < len(l.list.Elements), nil
+}
+
+func (l *listIterator) next() (LoxValue, RuntimeError) {
+	element := l.list.Elements[l.index]
+	l.index += 1
+	return element, nil
+}
+
+type stringIterator struct {
+	characters []rune
+	index      int
+}
+
+func (s *stringIterator) hasNext() (bool, RuntimeError) {
+	return s.index < len(s.characters), nil
+}
+
+func (s *stringIterator) next() (LoxValue, RuntimeError) {
+	character := string(s.characters[s.index])
+	s.index += 1
+	return character, nil
+}
+
+type rangeIterator struct {
+	current int64
+	end     int64
+}
+
+func (r *rangeIterator) hasNext() (bool, RuntimeError) {
+	return r.current < r.end, nil
+}
+
+func (r *rangeIterator) next() (LoxValue, RuntimeError) {
+	value := r.current
+	r.current += 1
+	return value, nil
+}
+
+// Drives an instance implementing hasNext() and next().
+type protocolIterator struct {
+	interpreter   *Interpreter
+	line          int
+	hasNextMethod *LoxFunction
+	nextMethod    *LoxFunction
+}
+
+func (p *protocolIterator) hasNext() (bool, RuntimeError) {
+	value, err := p.interpreter.callMethod(p.hasNextMethod, p.line)
+	return isTruthy(value), err
+}
+
+func (p *protocolIterator) next() (LoxValue, RuntimeError) {
+	return p.interpreter.callMethod(p.nextMethod, p.line)
+}
+
+func (i *Interpreter) iterator(token scanner.Token, value LoxValue) (iterator, RuntimeError) {
+	switch v := value.(type) {
+	case *LoxList:
+		return &listIterator{list: v}, nil
+	case string:
+		return &stringIterator{characters: []rune(v)}, nil
+	case *LoxRange:
+		return &rangeIterator{current: v.Start, end: v.End}, nil
+	case *LoxMap:
+		return &listIterator{list: NewLoxList(v.Keys())}, nil
+	case *LoxGenerator:
+		return v, nil
+	}
+
+	if method, hasMethod := protocolMethod(value, ITERATOR_METHOD); hasMethod {
+		iterable, err := i.callMethod(method, token.Line)
+		if err != nil {
+			return nil, err
+		}
+
+		// iterator() may hand back a built-in iterable such as a list or a generator.
+		if _, isInstance := iterable.(*LoxInstance); !isInstance {
+			return i.iterator(token, iterable)
+		}
+		value = iterable
+	}
+
+	hasNextMethod, hasHasNext := protocolMethod(value, HAS_NEXT_METHOD)
+	nextMethod, hasNext := protocolMethod(value, NEXT_METHOD)
+	if !hasHasNext || !hasNext {
+		return nil, NewRuntimeError(token.Line, fmt.Sprintf("Can't iterate over '%s'.", Stringify(value)))
+	}
+
+	return &protocolIterator{interpreter: i, line: token.Line, hasNextMethod: hasNextMethod, nextMethod: nextMethod}, nil
+}
@@ -0,0 +1,394 @@
+package interpeter
+
+import (
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/parser/statements"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type JsonNode = map[string]any
+
+type JsonPrinter struct {
+}
+
+func NewJsonPrinter() *JsonPrinter {
+	return &JsonPrinter{}
+}
+
+func (j *JsonPrinter) Print(statements []statements.Statement[LoxValue, RuntimeError]) []any {
+	return j.statementList(statements)
+}
+
+func (j *JsonPrinter) statement(statement statements.Statement[LoxValue, RuntimeError]) any {
+	if statement == nil {
+		return nil
+	}
+
+	node, _ := statement.Accept(j)
+	return node
+}
+
+func (j *JsonPrinter) statementList(statements []statements.Statement[LoxValue, RuntimeError]) []any {
+	nodes := []any{}
+	for _, statement := range statements {
+		nodes = append(nodes, j.statement(statement))
+	}
+	return nodes
+}
+
+func (j *JsonPrinter) expression(expression expressions.Expression[LoxValue, RuntimeError]) any {
+	if expression == nil {
+		return nil
+	}
+
+	node, _ := expression.Accept(j)
+	return node
+}
+
+func (j *JsonPrinter) expressionList(expressions []expressions.Expression[LoxValue, RuntimeError]) []any {
+	nodes := []any{}
+	for _, expression := range expressions {
+		nodes = append(nodes, j.expression(expression))
+	}
+	return nodes
+}
+
+func (j *JsonPrinter) statementNode(kind string, statement statements.Statement[LoxValue, RuntimeError]) JsonNode {
+	return JsonNode{"kind": kind, "line": statement.Line()}
+}
+
+func (j *JsonPrinter) tokenNode(kind string, token scanner.Token) JsonNode {
+	return JsonNode{"kind": kind, "line": token.Line, "column": token.Column}
+}
+
+func (j *JsonPrinter) VisitGroupingExpression(exp *expressions.Grouping[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Grouping", exp.Paren)
+	node["expression"] = j.expression(exp.Exp)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitBinaryExpression(exp *expressions.Binary[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	// Concatenations the parser lowered string interpolations into.
+	kind := "Binary"
+	if exp.Operator.Type == scanner.INTERPOLATION {
+		kind = "Interpolation"
+	}
+
+	node := j.tokenNode(kind, exp.Operator)
+	node["operator"] = exp.Operator.Lexeme
+	node["left"] = j.expression(exp.Left)
+	node["right"] = j.expression(exp.Right)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitUnaryExpression(exp *expressions.Unary[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Unary", exp.Operator)
+	node["operator"] = exp.Operator.Lexeme
+	node["right"] = j.expression(exp.Right)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitLiteralExpression(exp *expressions.Literal[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Literal", exp.Token)
+	node["type"] = literalType(exp.Literal)
+	node["value"] = exp.Literal
+	return node, nil
+}
+
+// Names the type of a literal, JSON numbers don't tell integers and floats apart.
+func literalType(value LoxValue) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+func (j *JsonPrinter) VisitVariableExpression(exp *expressions.Variable[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Variable", exp.Name)
+	node["name"] = exp.Name.Lexeme
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitAssignmentExpression(exp *expressions.Assignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Assignment", exp.Name)
+	node["name"] = exp.Name.Lexeme
+	node["operator"] = exp.Operator.Lexeme
+	node["value"] = j.expression(exp.Value)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitLogicalExpression(exp *expressions.Logical[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Logical", exp.Operator)
+	node["operator"] = exp.Operator.Lexeme
+	node["left"] = j.expression(exp.Left)
+	node["right"] = j.expression(exp.Right)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitCallExpression(exp *expressions.Call[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Call", exp.Parenthesis)
+	node["callee"] = j.expression(exp.Callee)
+	node["arguments"] = j.expressionList(exp.Params)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitGetExpression(exp *expressions.Get[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Get", exp.Name)
+	node["object"] = j.expression(exp.Object)
+	node["name"] = exp.Name.Lexeme
+	node["optional"] = exp.Optional
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitSetExpression(exp *expressions.Set[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Set", exp.Name)
+	node["object"] = j.expression(exp.Object)
+	node["name"] = exp.Name.Lexeme
+	node["operator"] = exp.Operator.Lexeme
+	node["value"] = j.expression(exp.Value)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitThisExpression(exp *expressions.This[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return j.tokenNode("This", exp.Keyword), nil
+}
+
+func (j *JsonPrinter) VisitListExpression(exp *expressions.List[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("List", exp.Bracket)
+	node["elements"] = j.expressionList(exp.Elements)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitMultiAssignmentExpression(exp *expressions.MultiAssignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("MultiAssignment", exp.Equals)
+	node["targets"] = j.expressionList(exp.Targets)
+	node["values"] = j.expressionList(exp.Values)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitIndexExpression(exp *expressions.Index[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Index", exp.Bracket)
+	node["object"] = j.expression(exp.Object)
+	node["index"] = j.expression(exp.Index)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitSetIndexExpression(exp *expressions.SetIndex[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("SetIndex", exp.Bracket)
+	node["object"] = j.expression(exp.Object)
+	node["index"] = j.expression(exp.Index)
+	node["operator"] = exp.Operator.Lexeme
+	node["value"] = j.expression(exp.Value)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitConditionalExpression(exp *expressions.Conditional[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Conditional", exp.Question)
+	node["condition"] = j.expression(exp.Condition)
+	node["then"] = j.expression(exp.ThenBranch)
+	node["else"] = j.expression(exp.ElseBranch)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitOptionalChainExpression(exp *expressions.OptionalChain[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return JsonNode{"kind": "OptionalChain", "chain": j.expression(exp.Chain)}, nil
+}
+
+func (j *JsonPrinter) VisitLambdaExpression(exp *expressions.Lambda[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	function := exp.Function.(*statements.Function[LoxValue, RuntimeError])
+	return j.function("Lambda", function), nil
+}
+
+func (j *JsonPrinter) VisitPrintStatement(statement *statements.Print[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.statementNode("Print", statement)
+	node["expression"] = j.expression(statement.Exp)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitExpressionStatement(statement *statements.Expression[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.statementNode("Expression", statement)
+	node["expression"] = j.expression(statement.Exp)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitVarStatement(statement *statements.Var[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if statement.Constant {
+		node := j.statementNode("Const", statement)
+		node["name"] = statement.Name.Lexeme
+		node["initializer"] = j.expression(statement.Initializer)
+		return node, nil
+	}
+
+	node := j.tokenNode("Var", statement.Name)
+	node["name"] = statement.Name.Lexeme
+	node["initializer"] = j.expression(statement.Initializer)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitBlockStatement(statement *statements.Block[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.statementNode("Block", statement)
+	node["statements"] = j.statementList(statement.Statements)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitIfStatement(statement *statements.If[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.statementNode("If", statement)
+	node["condition"] = j.expression(statement.Condition)
+	node["then"] = j.statement(statement.IfBranch)
+	node["else"] = j.statement(statement.ElseBranch)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitWhileStatement(statement *statements.While[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.statementNode("While", statement)
+	node["condition"] = j.expression(statement.Condition)
+	node["body"] = j.statement(statement.Body)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitYieldStatement(statement *statements.Yield[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Yield", statement.Keyword)
+	node["value"] = j.expression(statement.Value)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitForInStatement(statement *statements.ForIn[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("ForIn", statement.Keyword)
+	node["name"] = statement.Name.Lexeme
+	node["iterable"] = j.expression(statement.Iterable)
+	node["body"] = j.statement(statement.Body)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitAwaitExpression(exp *expressions.Await[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Await", exp.Keyword)
+	node["value"] = j.expression(exp.Value)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitRangeExpression(exp *expressions.Range[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Range", exp.Operator)
+	node["start"] = j.expression(exp.Start)
+	node["end"] = j.expression(exp.End)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitMatchStatement(statement *statements.Match[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	cases := []JsonNode{}
+	for _, matchCase := range statement.Cases {
+		node := j.tokenNode("Case", matchCase.Keyword)
+		node["pattern"] = j.casePattern(matchCase.Pattern)
+		node["body"] = j.statement(matchCase.Body)
+		cases = append(cases, node)
+	}
+
+	node := j.tokenNode("Match", statement.Keyword)
+	node["value"] = j.expression(statement.Value)
+	node["cases"] = cases
+	return node, nil
+}
+
+func (j *JsonPrinter) casePattern(pattern *statements.CasePattern[LoxValue, RuntimeError]) JsonNode {
+	node := j.tokenNode("Pattern", pattern.Token)
+	node["pattern"] = string(pattern.Kind)
+
+	switch pattern.Kind {
+	case statements.LITERAL_CASE:
+		node["value"] = pattern.Value
+	case statements.BINDING_CASE, statements.CLASS_CASE:
+		node["name"] = pattern.Token.Lexeme
+	}
+
+	if len(pattern.Patterns) > 0 {
+		var patterns []JsonNode
+		for _, subPattern := range pattern.Patterns {
+			patterns = append(patterns, j.casePattern(subPattern))
+		}
+		node["patterns"] = patterns
+	}
+	return node
+}
+
+func (j *JsonPrinter) function(kind string, statement *statements.Function[LoxValue, RuntimeError]) JsonNode {
+	params := []string{}
+	for _, param := range statement.Params {
+		params = append(params, param.Lexeme)
+	}
+
+	node := j.tokenNode(kind, statement.Name)
+	node["name"] = statement.Name.Lexeme
+	node["params"] = params
+	node["body"] = j.statementList(statement.Body)
+	if statement.Generator {
+		node["generator"] = true
+	}
+	if statement.Async {
+		node["async"] = true
+	}
+	return node
+}
+
+func (j *JsonPrinter) functionList(kind string, functions []statements.Function[LoxValue, RuntimeError]) []JsonNode {
+	nodes := []JsonNode{}
+	for _, function := range functions {
+		nodes = append(nodes, j.function(kind, &function))
+	}
+	return nodes
+}
+
+func (j *JsonPrinter) VisitDestructureStatement(statement *statements.Destructure[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	var names []any
+	for _, name := range statement.Pattern.Names {
+		names = append(names, name.Lexeme)
+	}
+
+	node := j.tokenNode("Destructure", statement.Pattern.Brace)
+	node["pattern"] = string(statement.Pattern.Kind)
+	node["names"] = names
+	node["constant"] = statement.Constant
+	node["initializer"] = j.expression(statement.Initializer)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitTraitStatement(statement *statements.Trait[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Trait", statement.Name)
+	node["name"] = statement.Name.Lexeme
+	node["methods"] = j.functionList("Method", statement.Methods)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitFunctionStatement(statement *statements.Function[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return j.function("Function", statement), nil
+}
+
+func (j *JsonPrinter) VisitReturnStatement(statement *statements.Return[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	node := j.tokenNode("Return", statement.Keyword)
+	node["value"] = j.expression(statement.Value)
+	return node, nil
+}
+
+func (j *JsonPrinter) VisitClassStatement(statement *statements.Class[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	traits := []string{}
+	for _, trait := range statement.Traits {
+		traits = append(traits, trait.Name.Lexeme)
+	}
+
+	node := j.tokenNode("Class", statement.Name)
+	node["name"] = statement.Name.Lexeme
+	node["traits"] = traits
+	node["methods"] = j.functionList("Method", statement.Methods)
+	node["staticMethods"] = j.functionList("StaticMethod", statement.StaticMethods)
+	node["getters"] = j.functionList("Getter", statement.Getters)
+	node["setters"] = j.functionList("Setter", statement.Setters)
+	return node, nil
+}
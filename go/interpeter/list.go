@@ -0,0 +1,36 @@
+package interpeter
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+	"strings"
+)
+
+type LoxList struct {
+	Stringifyable
+	Elements []LoxValue
+}
+
+func NewLoxList(elements []LoxValue) *LoxList {
+	return &LoxList{Elements: elements}
+}
+
+func (l *LoxList) ToString() string {
+	var elements []string
+	for _, element := range l.Elements {
+		elements = append(elements, Stringify(element))
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+func elementIndex(bracket scanner.Token, index LoxValue, length int) (int, RuntimeError) {
+	position, isInt := index.(int64)
+	if !isInt {
+		return 0, NewRuntimeError(bracket.Line, "Index must be an integer.")
+	}
+
+	if position < 0 || position >= int64(length) {
+		return 0, NewRuntimeError(bracket.Line, "Index out of range.")
+	}
+
+	return int(position), nil
+}
@@ -0,0 +1,119 @@
+package interpeter
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+type timer struct {
+	due      time.Time
+	id       int64
+	callback func() RuntimeError
+}
+
+// The event loop runs queued tasks and timers after the script, or while it awaits a promise.
+type eventLoop struct {
+	tasks    []func() RuntimeError
+	timers   []*timer
+	sequence int64
+	rejected []*LoxPromise
+	// The coroutine interpreter that currently runs, nil while the main interpreter does.
+	running *Interpreter
+
+	// Coroutines that started and haven't finished yet.
+	coroutines map[*coroutine]bool
+	// Coroutines whose generator or fiber was garbage collected. The collector queues them from
+	// its own goroutine, they are cancelled the next time a coroutine is resumed.
+	abandonedMutex sync.Mutex
+	abandoned      []*coroutine
+}
+
+func newEventLoop() *eventLoop {
+	return &eventLoop{coroutines: map[*coroutine]bool{}}
+}
+
+// Makes the coroutine get cancelled once the value that resumes it is garbage collected. The
+// coroutine must not refer to the value, or it never becomes unreachable.
+func (l *eventLoop) cancelWhenUnreachable(value any, coroutine *coroutine) {
+	runtime.SetFinalizer(value, func(any) {
+		l.abandonedMutex.Lock()
+		defer l.abandonedMutex.Unlock()
+		l.abandoned = append(l.abandoned, coroutine)
+	})
+}
+
+func (l *eventLoop) cancelAbandoned() {
+	l.abandonedMutex.Lock()
+	abandoned := l.abandoned
+	l.abandoned = nil
+	l.abandonedMutex.Unlock()
+
+	for _, coroutine := range abandoned {
+		coroutine.cancel()
+	}
+}
+
+// Cancels every coroutine that is still suspended.
+func (l *eventLoop) close() {
+	l.cancelAbandoned()
+	for coroutine := range l.coroutines {
+		coroutine.cancel()
+	}
+}
+
+func (l *eventLoop) enqueue(task func() RuntimeError) {
+	l.tasks = append(l.tasks, task)
+}
+
+func (l *eventLoop) schedule(delay time.Duration, callback func() RuntimeError) int64 {
+	l.sequence += 1
+	l.timers = append(l.timers, &timer{due: time.Now().Add(delay), id: l.sequence, callback: callback})
+	return l.sequence
+}
+
+func (l *eventLoop) pending() bool {
+	return len(l.tasks) > 0 || len(l.timers) > 0
+}
+
+func (l *eventLoop) runOnce() RuntimeError {
+	if len(l.tasks) > 0 {
+		task := l.tasks[0]
+		l.tasks = l.tasks[1:]
+		return task()
+	}
+
+	sort.SliceStable(l.timers, func(a, b int) bool {
+		return l.timers[a].due.Before(l.timers[b].due)
+	})
+
+	next := l.timers[0]
+	l.timers = l.timers[1:]
+	time.Sleep(time.Until(next.due))
+	return next.callback()
+}
+
+func (l *eventLoop) runUntil(done func() bool) RuntimeError {
+	for !done() && l.pending() {
+		if err := l.runOnce(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *eventLoop) run() RuntimeError {
+	if err := l.runUntil(func() bool { return false }); err != nil {
+		return err
+	}
+
+	rejected := l.rejected
+	l.rejected = nil
+	for _, promise := range rejected {
+		if !promise.handled {
+			return promise.err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/scanner"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// A LoxMap maps keys to values and remembers the order keys were added in, which is the order
+// for-in loops visit them in. Keys are compared like ==, so 1 and 1.0 are the same key.
+type LoxMap struct {
+	Stringifyable
+	keys   []LoxValue
+	values map[LoxValue]LoxValue
+}
+
+func NewLoxMap() *LoxMap {
+	return &LoxMap{values: map[LoxValue]LoxValue{}}
+}
+
+// Returns the key the value is stored under, integral floats are stored like integers.
+func mapKey(key LoxValue) (LoxValue, bool) {
+	if number, isFloat := key.(float64); isFloat && number == math.Trunc(number) && math.Abs(number) < math.MaxInt64 {
+		return int64(number), true
+	}
+
+	return key, key == nil || reflect.TypeOf(key).Comparable()
+}
+
+// Returns the value stored under the key, or nil if there is none.
+func (m *LoxMap) lookup(key LoxValue) LoxValue {
+	if key, ok := mapKey(key); ok {
+		return m.values[key]
+	}
+	return nil
+}
+
+func (m *LoxMap) has(key LoxValue) bool {
+	key, ok := mapKey(key)
+	if !ok {
+		return false
+	}
+
+	_, has := m.values[key]
+	return has
+}
+
+func (m *LoxMap) set(line int, key LoxValue, value LoxValue) RuntimeError {
+	key, ok := mapKey(key)
+	if !ok {
+		return NewRuntimeError(line, fmt.Sprintf("Can't use '%s' as a map key.", Stringify(key)))
+	}
+
+	if _, has := m.values[key]; !has {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return nil
+}
+
+func (m *LoxMap) remove(key LoxValue) bool {
+	key, ok := mapKey(key)
+	if !ok {
+		return false
+	}
+
+	if _, has := m.values[key]; !has {
+		return false
+	}
+
+	delete(m.values, key)
+	for index, existing := range m.keys {
+		if existing == key {
+			m.keys = append(m.keys[:index], m.keys[index+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Returns a copy of the keys, so the map can be changed while they are iterated.
+func (m *LoxMap) Keys() []LoxValue {
+	return append([]LoxValue{}, m.keys...)
+}
+
+func (m *LoxMap) get(name scanner.Token) (LoxValue, RuntimeError) {
+	switch name.Lexeme {
+	case "get":
+		return NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return m.lookup(args[0]), nil
+		}), nil
+	case "set":
+		return NewLoxCallable(2, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return m, m.set(interpreter.currentFrame().Line, args[0], args[1])
+		}), nil
+	case "has":
+		return NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return m.has(args[0]), nil
+		}), nil
+	case "remove":
+		return NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return m.remove(args[0]), nil
+		}), nil
+	case "keys":
+		return NewLoxCallable(0, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+			return NewLoxList(m.Keys()), nil
+		}), nil
+	}
+
+	return nil, NewRuntimeError(name.Line, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+func (m *LoxMap) ToString() string {
+	var entries []string
+	for _, key := range m.keys {
+		entries = append(entries, Stringify(key)+": "+Stringify(m.values[key]))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func defineMapGlobals(globals *Environment) {
+	globals.define("Map", NewLoxCallable(0, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		return NewLoxMap(), nil
+	}))
+}
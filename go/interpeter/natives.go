@@ -0,0 +1,117 @@
+package interpeter
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+func (i *Interpreter) nativeError(message string) RuntimeError {
+	return NewRuntimeError(i.currentFrame().Line, message)
+}
+
+func (i *Interpreter) stringArgument(name string, value LoxValue) (string, RuntimeError) {
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	return "", i.nativeError(fmt.Sprintf("Argument to '%s' must be a string.", name))
+}
+
+func (i *Interpreter) indexArgument(name string, value LoxValue) (int, RuntimeError) {
+	switch number := value.(type) {
+	case int64:
+		return int(number), nil
+	case float64:
+		if number == math.Trunc(number) {
+			return int(number), nil
+		}
+	}
+	return 0, i.nativeError(fmt.Sprintf("Index passed to '%s' must be an integer.", name))
+}
+
+func defineListGlobals(globals *Environment) {
+	globals.define("push", NewLoxCallable(2, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		list, ok := args[0].(*LoxList)
+		if !ok {
+			return nil, interpreter.nativeError("Argument to 'push' must be a list.")
+		}
+
+		list.Elements = append(list.Elements, args[1])
+		return list, nil
+	}))
+}
+
+func defineInstanceGlobals(globals *Environment) {
+	globals.define("freeze", NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		instance, ok := args[0].(*LoxInstance)
+		if !ok {
+			return nil, interpreter.nativeError("Argument to 'freeze' must be an instance.")
+		}
+
+		instance.Frozen = true
+		return instance, nil
+	}))
+
+	globals.define("isFrozen", NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		instance, ok := args[0].(*LoxInstance)
+		return ok && instance.Frozen, nil
+	}))
+}
+
+func defineStringGlobals(globals *Environment) {
+	globals.define("len", NewLoxCallable(1, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		if list, ok := args[0].(*LoxList); ok {
+			return int64(len(list.Elements)), nil
+		}
+		if dictionary, ok := args[0].(*LoxMap); ok {
+			return int64(len(dictionary.keys)), nil
+		}
+
+		text, err := interpreter.stringArgument("len", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return int64(utf8.RuneCountInString(text)), nil
+	}))
+
+	globals.define("charAt", NewLoxCallable(2, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		text, err := interpreter.stringArgument("charAt", args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := interpreter.indexArgument("charAt", args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		characters := []rune(text)
+		if index < 0 || index >= len(characters) {
+			return nil, interpreter.nativeError("String index out of range.")
+		}
+		return string(characters[index]), nil
+	}))
+
+	globals.define("substring", NewLoxCallable(3, func(interpreter *Interpreter, args []LoxValue) (LoxValue, RuntimeError) {
+		text, err := interpreter.stringArgument("substring", args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := interpreter.indexArgument("substring", args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := interpreter.indexArgument("substring", args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		characters := []rune(text)
+		if start < 0 || end > len(characters) || start > end {
+			return nil, interpreter.nativeError("String index out of range.")
+		}
+		return string(characters[start:end]), nil
+	}))
+}
@@ -0,0 +1,119 @@
+package interpeter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Numbers are either exact int64 integers or float64 values. Operations on two integers stay
+// integers, mixed operations promote the integer to a float.
+
+func isNumber(value LoxValue) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat(value LoxValue) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func toInts(left LoxValue, right LoxValue) (int64, int64, bool) {
+	leftInt, leftOk := left.(int64)
+	rightInt, rightOk := right.(int64)
+	return leftInt, rightInt, leftOk && rightOk
+}
+
+func toFloats(left LoxValue, right LoxValue) (float64, float64, bool) {
+	leftFloat, leftOk := toFloat(left)
+	rightFloat, rightOk := toFloat(right)
+	return leftFloat, rightFloat, leftOk && rightOk
+}
+
+func addInts(left int64, right int64) (int64, bool) {
+	result := left + right
+	overflow := (left > 0 && right > 0 && result < 0) || (left < 0 && right < 0 && result >= 0)
+	return result, !overflow
+}
+
+func subtractInts(left int64, right int64) (int64, bool) {
+	if right == math.MinInt64 {
+		return left - right, left < 0
+	}
+	return addInts(left, -right)
+}
+
+func multiplyInts(left int64, right int64) (int64, bool) {
+	if left == 0 || right == 0 {
+		return 0, true
+	}
+
+	result := left * right
+	overflow := result/right != left || (left == -1 && right == math.MinInt64) || (right == -1 && left == math.MinInt64)
+	return result, !overflow
+}
+
+// Integer division stays exact when the quotient is an integer and promotes to a float otherwise.
+func divideInts(left int64, right int64) LoxValue {
+	if left%right == 0 && !(left == math.MinInt64 && right == -1) {
+		return left / right
+	}
+	return float64(left) / float64(right)
+}
+
+// Floats always show a decimal point or an exponent, so 1.0 can be told apart from the integer 1.
+func formatFloat(value float64) string {
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	if math.IsInf(value, 0) || math.IsNaN(value) || strings.ContainsAny(text, ".e") {
+		return text
+	}
+	return text + ".0"
+}
+
+func numbersEqual(left LoxValue, right LoxValue) bool {
+	if leftInt, rightInt, ok := toInts(left, right); ok {
+		return leftInt == rightInt
+	}
+
+	leftFloat, rightFloat, _ := toFloats(left, right)
+	return leftFloat == rightFloat
+}
+
+func isEqual(left LoxValue, right LoxValue) bool {
+	if isNumber(left) && isNumber(right) {
+		return numbersEqual(left, right)
+	}
+	return left == right
+}
+
+// Raises to a non-negative integer power by repeated squaring, reporting overflows.
+func powerInts(base int64, exponent int64) (int64, bool) {
+	result := int64(1)
+	for exponent > 0 {
+		var ok bool
+		if exponent&1 == 1 {
+			if result, ok = multiplyInts(result, base); !ok {
+				return 0, false
+			}
+		}
+
+		exponent >>= 1
+		if exponent > 0 {
+			if base, ok = multiplyInts(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
@@ -0,0 +1,458 @@
+package interpeter
+
+import (
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/parser/statements"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+// The Optimizer rewrites a resolved syntax tree in place. It folds constant expressions, drops
+// branches that can never run, removes redundant double negations and inlines global variables
+// that always hold the same literal. The rewritten tree behaves the same as the original one.
+type Optimizer struct {
+	locals map[expressions.Expression[LoxValue, RuntimeError]]int
+	// Evaluates constant expressions, so folding follows exactly the rules of the interpreter.
+	evaluator *Interpreter
+
+	// Literal globals that reads may be replaced with, only set once their declaration has run.
+	constants map[string]*expressions.Literal[LoxValue, RuntimeError]
+}
+
+func NewOptimizer(locals map[expressions.Expression[LoxValue, RuntimeError]]int) *Optimizer {
+	evaluator := NewInterpreter()
+	return &Optimizer{
+		locals:    locals,
+		evaluator: &evaluator,
+		constants: map[string]*expressions.Literal[LoxValue, RuntimeError]{},
+	}
+}
+
+func (o *Optimizer) Optimize(program []statements.Statement[LoxValue, RuntimeError]) []statements.Statement[LoxValue, RuntimeError] {
+	// Globals are inlined while folding, once their declaration has been folded into a literal.
+	// Reads before the declaration keep looking the variable up, they may run before it is
+	// defined.
+	candidates := o.unchangedGlobals(program)
+	optimized := make([]statements.Statement[LoxValue, RuntimeError], 0, len(program))
+	for _, statement := range program {
+		if statement = o.statement(statement); statement != nil {
+			optimized = append(optimized, statement)
+		}
+
+		if declaration, ok := statement.(*statements.Var[LoxValue, RuntimeError]); ok && candidates[declaration.Name.Lexeme] {
+			if literal, isLiteral := declaration.Initializer.(*expressions.Literal[LoxValue, RuntimeError]); isLiteral {
+				o.constants[declaration.Name.Lexeme] = literal
+			}
+		}
+	}
+
+	return optimized
+}
+
+// Finds the globals that are declared once by a var declaration and never assigned.
+func (o *Optimizer) unchangedGlobals(program []statements.Statement[LoxValue, RuntimeError]) map[string]bool {
+	declarations := map[string]int{}
+	candidates := map[string]bool{}
+
+	for _, statement := range program {
+		switch s := statement.(type) {
+		case *statements.Var[LoxValue, RuntimeError]:
+			declarations[s.Name.Lexeme]++
+			candidates[s.Name.Lexeme] = true
+		case *statements.Destructure[LoxValue, RuntimeError]:
+			for _, name := range s.Pattern.Names {
+				declarations[name.Lexeme]++
+			}
+		case *statements.Function[LoxValue, RuntimeError]:
+			declarations[s.Name.Lexeme]++
+		case *statements.Class[LoxValue, RuntimeError]:
+			declarations[s.Name.Lexeme]++
+		case *statements.Trait[LoxValue, RuntimeError]:
+			declarations[s.Name.Lexeme]++
+		}
+	}
+
+	for _, node := range syntaxTreeExpressions(program) {
+		switch exp := node.(type) {
+		case *expressions.Assignment[LoxValue, RuntimeError]:
+			if !o.isLocal(exp) {
+				delete(candidates, exp.Name.Lexeme)
+			}
+		case *expressions.MultiAssignment[LoxValue, RuntimeError]:
+			for _, target := range exp.Targets {
+				if variable, ok := target.(*expressions.Variable[LoxValue, RuntimeError]); ok && !o.isLocal(variable) {
+					delete(candidates, variable.Name.Lexeme)
+				}
+			}
+		}
+	}
+
+	for name := range candidates {
+		if declarations[name] != 1 {
+			delete(candidates, name)
+		}
+	}
+
+	return candidates
+}
+
+func (o *Optimizer) isLocal(exp expressions.Expression[LoxValue, RuntimeError]) bool {
+	_, ok := o.locals[exp]
+	return ok
+}
+
+func (o *Optimizer) expression(exp expressions.Expression[LoxValue, RuntimeError]) expressions.Expression[LoxValue, RuntimeError] {
+	if exp == nil {
+		return nil
+	}
+
+	optimized, _ := exp.Accept(o)
+	return optimized.(expressions.Expression[LoxValue, RuntimeError])
+}
+
+func (o *Optimizer) expressionList(exps []expressions.Expression[LoxValue, RuntimeError]) []expressions.Expression[LoxValue, RuntimeError] {
+	for index, exp := range exps {
+		exps[index] = o.expression(exp)
+	}
+	return exps
+}
+
+// Returns nil when the statement can be dropped.
+func (o *Optimizer) statement(statement statements.Statement[LoxValue, RuntimeError]) statements.Statement[LoxValue, RuntimeError] {
+	if statement == nil {
+		return nil
+	}
+
+	optimized, _ := statement.Accept(o)
+	if optimized == nil {
+		return nil
+	}
+	return optimized.(statements.Statement[LoxValue, RuntimeError])
+}
+
+func (o *Optimizer) statementList(list []statements.Statement[LoxValue, RuntimeError]) []statements.Statement[LoxValue, RuntimeError] {
+	optimized := make([]statements.Statement[LoxValue, RuntimeError], 0, len(list))
+	for _, statement := range list {
+		if statement = o.statement(statement); statement != nil {
+			optimized = append(optimized, statement)
+		}
+	}
+	return optimized
+}
+
+// Like statement, but for places that need a statement even when it was dropped.
+func (o *Optimizer) body(statement statements.Statement[LoxValue, RuntimeError], line int) statements.Statement[LoxValue, RuntimeError] {
+	if optimized := o.statement(statement); optimized != nil {
+		return optimized
+	}
+	return statements.NewBlock[LoxValue, RuntimeError](line, nil)
+}
+
+func (o *Optimizer) function(function *statements.Function[LoxValue, RuntimeError]) {
+	function.Body = o.statementList(function.Body)
+}
+
+// Optimizes an expression that is only checked for truthiness, where !!x is the same as x.
+func (o *Optimizer) condition(exp expressions.Expression[LoxValue, RuntimeError]) expressions.Expression[LoxValue, RuntimeError] {
+	exp = o.expression(exp)
+	for {
+		outer, ok := exp.(*expressions.Unary[LoxValue, RuntimeError])
+		if !ok || outer.Operator.Type != scanner.BANG {
+			return exp
+		}
+
+		inner, ok := outer.Right.(*expressions.Unary[LoxValue, RuntimeError])
+		if !ok || inner.Operator.Type != scanner.BANG {
+			return exp
+		}
+		exp = inner.Right
+	}
+}
+
+func literalValue(exp expressions.Expression[LoxValue, RuntimeError]) (LoxValue, bool) {
+	if literal, ok := exp.(*expressions.Literal[LoxValue, RuntimeError]); ok {
+		return literal.Literal, true
+	}
+	return nil, false
+}
+
+// Evaluates an expression whose operands are all literals. Expressions that fail or don't
+// produce a primitive value are kept, so their errors still happen at runtime.
+func (o *Optimizer) fold(exp expressions.Expression[LoxValue, RuntimeError], token scanner.Token) expressions.Expression[LoxValue, RuntimeError] {
+	value, err := o.evaluator.evaluate(exp)
+	if err != nil {
+		return exp
+	}
+
+	switch value.(type) {
+	case nil, bool, float64, int64, string:
+		return expressions.NewLiteral[LoxValue, RuntimeError](token, value)
+	}
+	return exp
+}
+
+func (o *Optimizer) VisitGroupingExpression(exp *expressions.Grouping[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Exp = o.expression(exp.Exp)
+	if _, ok := literalValue(exp.Exp); ok {
+		return exp.Exp, nil
+	}
+	return exp, nil
+}
+
+func (o *Optimizer) VisitBinaryExpression(exp *expressions.Binary[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Left = o.expression(exp.Left)
+	exp.Right = o.expression(exp.Right)
+
+	_, leftIsLiteral := literalValue(exp.Left)
+	_, rightIsLiteral := literalValue(exp.Right)
+	if leftIsLiteral && rightIsLiteral {
+		return o.fold(exp, exp.Operator), nil
+	}
+	return exp, nil
+}
+
+func (o *Optimizer) VisitUnaryExpression(exp *expressions.Unary[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Right = o.expression(exp.Right)
+
+	if _, ok := literalValue(exp.Right); ok {
+		return o.fold(exp, exp.Operator), nil
+	}
+
+	// !!x is only the same as x when x is already a boolean, which !y always is.
+	if exp.Operator.Type == scanner.BANG {
+		if inner, ok := exp.Right.(*expressions.Unary[LoxValue, RuntimeError]); ok && inner.Operator.Type == scanner.BANG {
+			if innermost, ok := inner.Right.(*expressions.Unary[LoxValue, RuntimeError]); ok && innermost.Operator.Type == scanner.BANG {
+				return innermost, nil
+			}
+		}
+	}
+
+	return exp, nil
+}
+
+func (o *Optimizer) VisitLiteralExpression(exp *expressions.Literal[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return exp, nil
+}
+
+func (o *Optimizer) VisitVariableExpression(exp *expressions.Variable[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if literal, ok := o.constants[exp.Name.Lexeme]; ok && !o.isLocal(exp) {
+		return expressions.NewLiteral[LoxValue, RuntimeError](exp.Name, literal.Literal), nil
+	}
+	return exp, nil
+}
+
+func (o *Optimizer) VisitAssignmentExpression(exp *expressions.Assignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Value = o.expression(exp.Value)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitLogicalExpression(exp *expressions.Logical[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Left = o.expression(exp.Left)
+	exp.Right = o.expression(exp.Right)
+
+	left, ok := literalValue(exp.Left)
+	if !ok {
+		return exp, nil
+	}
+
+	switch exp.Operator.Type {
+	case scanner.OR:
+		if isTruthy(left) {
+			return exp.Left, nil
+		}
+	case scanner.QUESTION_QUESTION:
+		if left != nil {
+			return exp.Left, nil
+		}
+	default:
+		if !isTruthy(left) {
+			return exp.Left, nil
+		}
+	}
+
+	return exp.Right, nil
+}
+
+func (o *Optimizer) VisitCallExpression(exp *expressions.Call[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Callee = o.expression(exp.Callee)
+	exp.Params = o.expressionList(exp.Params)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitGetExpression(exp *expressions.Get[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Object = o.expression(exp.Object)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitSetExpression(exp *expressions.Set[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Object = o.expression(exp.Object)
+	exp.Value = o.expression(exp.Value)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitThisExpression(exp *expressions.This[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return exp, nil
+}
+
+func (o *Optimizer) VisitListExpression(exp *expressions.List[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Elements = o.expressionList(exp.Elements)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitIndexExpression(exp *expressions.Index[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Object = o.expression(exp.Object)
+	exp.Index = o.expression(exp.Index)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitSetIndexExpression(exp *expressions.SetIndex[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Object = o.expression(exp.Object)
+	exp.Index = o.expression(exp.Index)
+	exp.Value = o.expression(exp.Value)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitConditionalExpression(exp *expressions.Conditional[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Condition = o.condition(exp.Condition)
+	exp.ThenBranch = o.expression(exp.ThenBranch)
+	exp.ElseBranch = o.expression(exp.ElseBranch)
+
+	if condition, ok := literalValue(exp.Condition); ok {
+		if isTruthy(condition) {
+			return exp.ThenBranch, nil
+		}
+		return exp.ElseBranch, nil
+	}
+	return exp, nil
+}
+
+func (o *Optimizer) VisitOptionalChainExpression(exp *expressions.OptionalChain[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Chain = o.expression(exp.Chain)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitLambdaExpression(exp *expressions.Lambda[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	o.function(exp.Function.(*statements.Function[LoxValue, RuntimeError]))
+	return exp, nil
+}
+
+func (o *Optimizer) VisitMultiAssignmentExpression(exp *expressions.MultiAssignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	for _, target := range exp.Targets {
+		// Only the objects and indices of targets are evaluated, never the targets themselves.
+		switch t := target.(type) {
+		case *expressions.Get[LoxValue, RuntimeError]:
+			t.Object = o.expression(t.Object)
+		case *expressions.Index[LoxValue, RuntimeError]:
+			t.Object = o.expression(t.Object)
+			t.Index = o.expression(t.Index)
+		}
+	}
+	exp.Values = o.expressionList(exp.Values)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitRangeExpression(exp *expressions.Range[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Start = o.expression(exp.Start)
+	exp.End = o.expression(exp.End)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitAwaitExpression(exp *expressions.Await[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	exp.Value = o.expression(exp.Value)
+	return exp, nil
+}
+
+func (o *Optimizer) VisitPrintStatement(statement *statements.Print[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Exp = o.expression(statement.Exp)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitExpressionStatement(statement *statements.Expression[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Exp = o.expression(statement.Exp)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitVarStatement(statement *statements.Var[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Initializer = o.expression(statement.Initializer)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitBlockStatement(statement *statements.Block[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Statements = o.statementList(statement.Statements)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitIfStatement(statement *statements.If[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Condition = o.condition(statement.Condition)
+
+	if condition, ok := literalValue(statement.Condition); ok {
+		if isTruthy(condition) {
+			return o.statement(statement.IfBranch), nil
+		}
+		return o.statement(statement.ElseBranch), nil
+	}
+
+	statement.IfBranch = o.body(statement.IfBranch, statement.SourceLine)
+	statement.ElseBranch = o.statement(statement.ElseBranch)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitWhileStatement(statement *statements.While[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Condition = o.condition(statement.Condition)
+
+	if condition, ok := literalValue(statement.Condition); ok && !isTruthy(condition) {
+		return nil, nil
+	}
+
+	statement.Body = o.body(statement.Body, statement.SourceLine)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitFunctionStatement(statement *statements.Function[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	o.function(statement)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitReturnStatement(statement *statements.Return[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Value = o.expression(statement.Value)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitClassStatement(statement *statements.Class[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	for _, methods := range [][]statements.Function[LoxValue, RuntimeError]{statement.Methods, statement.StaticMethods, statement.Getters, statement.Setters} {
+		for index := range methods {
+			o.function(&methods[index])
+		}
+	}
+	return statement, nil
+}
+
+func (o *Optimizer) VisitTraitStatement(statement *statements.Trait[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	for index := range statement.Methods {
+		o.function(&statement.Methods[index])
+	}
+	return statement, nil
+}
+
+func (o *Optimizer) VisitDestructureStatement(statement *statements.Destructure[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Initializer = o.expression(statement.Initializer)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitMatchStatement(statement *statements.Match[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Value = o.expression(statement.Value)
+	for index := range statement.Cases {
+		statement.Cases[index].Body = o.body(statement.Cases[index].Body, statement.Cases[index].Keyword.Line)
+	}
+	return statement, nil
+}
+
+func (o *Optimizer) VisitForInStatement(statement *statements.ForIn[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Iterable = o.expression(statement.Iterable)
+	statement.Body = o.body(statement.Body, statement.Keyword.Line)
+	return statement, nil
+}
+
+func (o *Optimizer) VisitYieldStatement(statement *statements.Yield[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	statement.Value = o.expression(statement.Value)
+	return statement, nil
+}
@@ -0,0 +1,98 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/parser/statements"
+	"io"
+)
+
+// A Program is a parsed and resolved script. It is never modified after Compile returns, so
+// one Program can be run by any number of interpreters at the same time, each on its own
+// goroutine. All runtime state (environments, instances, the event loop) belongs to the
+// interpreter running the program and must not be shared with other interpreters.
+type Program struct {
+	statements []statements.Statement[LoxValue, RuntimeError]
+	locals     map[expressions.Expression[LoxValue, RuntimeError]]int
+	warnings   []RuntimeError
+}
+
+// Resolves the statements and, when optimize is set, runs the Optimizer over them.
+func Compile(statements []statements.Statement[LoxValue, RuntimeError], optimize bool) (*Program, RuntimeError) {
+	program := &Program{
+		statements: statements,
+		locals:     map[expressions.Expression[LoxValue, RuntimeError]]int{},
+	}
+
+	resolver := newResolver(program.locals, nil)
+	if err := resolver.Resolve(statements); err != nil {
+		return nil, err
+	}
+
+	program.warnings = resolver.Warnings()
+	if optimize {
+		program.statements = NewOptimizer(program.locals).Optimize(program.statements)
+	}
+
+	return program, nil
+}
+
+func (p *Program) Statements() []statements.Statement[LoxValue, RuntimeError] {
+	return p.statements
+}
+
+func (p *Program) Warnings() []RuntimeError {
+	return p.warnings
+}
+
+func (i *Interpreter) SetOutput(out io.Writer) {
+	i.out = out
+}
+
+func (i *Interpreter) Run(program *Program) (LoxValue, *Environment, RuntimeError) {
+	i.locals = program.locals
+	for _, hook := range i.hooks {
+		hook.BeforeProgram(i, program)
+	}
+	return i.Interpret(program.statements)
+}
+
+// Cancels the generators, fibers and async functions the interpreter started that are still
+// suspended. Called once nothing is going to resume them anymore.
+func (i *Interpreter) Close() {
+	i.loop.close()
+}
+
+// Calls the global function with the given name, after Run defined it, and then runs the event
+// loop until everything the call started is done. A returned promise is awaited.
+func (i *Interpreter) CallGlobal(name string, args ...LoxValue) (LoxValue, RuntimeError) {
+	value, ok := i.globals.Lookup(name)
+	if !ok {
+		return nil, i.nativeError(fmt.Sprintf("Undefined variable '%s'.", name))
+	}
+
+	callable, isCallable := value.(Callable)
+	if !isCallable {
+		return nil, i.nativeError("Can only call functions and classes.")
+	}
+
+	if arity := callable.Arity(); arity != VARIADIC_ARITY && len(args) != arity {
+		return nil, i.nativeError(fmt.Sprintf("Expected %d arguments but got %d.", arity, len(args)))
+	}
+
+	result, err := callable.Call(i, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if promise, isPromise := result.(*LoxPromise); isPromise {
+		if result, err = i.await(promise); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := i.loop.run(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package interpeter
+
+import (
+	"encoding/gob"
+	"fmt"
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/parser/statements"
+	"io"
+	"reflect"
+)
+
+// Must be increased whenever the shape of the syntax tree changes, so that programs encoded by an
+// older version are not decoded into the wrong nodes.
+const PROGRAM_FORMAT_VERSION = 2
+
+type encodedProgram struct {
+	Version    int
+	Statements []statements.Statement[LoxValue, RuntimeError]
+	// The scope depth of each resolved expression, keyed by its position in the syntax tree.
+	Locals   map[int]int
+	Warnings []encodedWarning
+}
+
+type encodedWarning struct {
+	Line    int
+	Message string
+}
+
+func init() {
+	gob.Register(&expressions.Grouping[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Binary[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Unary[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Literal[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Variable[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Assignment[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Logical[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Call[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Get[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Set[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.This[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.List[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Index[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.SetIndex[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Conditional[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.OptionalChain[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Lambda[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.MultiAssignment[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Range[LoxValue, RuntimeError]{})
+	gob.Register(&expressions.Await[LoxValue, RuntimeError]{})
+
+	gob.Register(&statements.Print[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Expression[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Var[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Block[LoxValue, RuntimeError]{})
+	gob.Register(&statements.If[LoxValue, RuntimeError]{})
+	gob.Register(&statements.While[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Function[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Return[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Class[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Trait[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Destructure[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Match[LoxValue, RuntimeError]{})
+	gob.Register(&statements.ForIn[LoxValue, RuntimeError]{})
+	gob.Register(&statements.Yield[LoxValue, RuntimeError]{})
+}
+
+// Writes the program, including its resolution results, in a form that DecodeProgram can read.
+func (p *Program) Encode(w io.Writer) error {
+	encoded := encodedProgram{
+		Version:    PROGRAM_FORMAT_VERSION,
+		Statements: p.statements,
+		Locals:     map[int]int{},
+	}
+
+	for index, node := range syntaxTreeExpressions(p.statements) {
+		if depth, ok := p.locals[node]; ok {
+			encoded.Locals[index] = depth
+		}
+	}
+
+	for _, warning := range p.warnings {
+		encoded.Warnings = append(encoded.Warnings, encodedWarning{Line: warning.Line(), Message: warning.Message()})
+	}
+
+	return gob.NewEncoder(w).Encode(encoded)
+}
+
+func DecodeProgram(r io.Reader) (*Program, error) {
+	var encoded encodedProgram
+	if err := gob.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, err
+	}
+
+	if encoded.Version != PROGRAM_FORMAT_VERSION {
+		return nil, fmt.Errorf("unsupported program format version %d", encoded.Version)
+	}
+
+	program := &Program{
+		statements: encoded.Statements,
+		locals:     map[expressions.Expression[LoxValue, RuntimeError]]int{},
+	}
+
+	for index, node := range syntaxTreeExpressions(program.statements) {
+		if depth, ok := encoded.Locals[index]; ok {
+			program.locals[node] = depth
+		}
+	}
+
+	for _, warning := range encoded.Warnings {
+		program.warnings = append(program.warnings, NewRuntimeError(warning.Line, warning.Message))
+	}
+
+	return program, nil
+}
+
+// Lists every expression node of the syntax tree in a fixed order. The order only depends on the
+// shape of the tree, so it identifies the same nodes before encoding and after decoding.
+func syntaxTreeExpressions(program []statements.Statement[LoxValue, RuntimeError]) []expressions.Expression[LoxValue, RuntimeError] {
+	var nodes []expressions.Expression[LoxValue, RuntimeError]
+	WalkSyntaxTree(program, func(node any) {
+		if exp, ok := node.(expressions.Expression[LoxValue, RuntimeError]); ok {
+			nodes = append(nodes, exp)
+		}
+	})
+	return nodes
+}
+
+// Calls visit with every statement and expression node of the syntax tree, parents before their
+// children and siblings in order.
+func WalkSyntaxTree(program []statements.Statement[LoxValue, RuntimeError], visit func(node any)) {
+	var walk func(value reflect.Value)
+	walk = func(value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if !value.IsNil() {
+				walk(value.Elem())
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				walk(value.Index(i))
+			}
+		case reflect.Struct:
+			if value.CanAddr() {
+				switch node := value.Addr().Interface().(type) {
+				case expressions.Expression[LoxValue, RuntimeError], statements.Statement[LoxValue, RuntimeError]:
+					visit(node)
+				}
+			}
+
+			for i := 0; i < value.NumField(); i++ {
+				if value.Type().Field(i).IsExported() {
+					walk(value.Field(i))
+				}
+			}
+		}
+	}
+
+	walk(reflect.ValueOf(program))
+}
@@ -0,0 +1,56 @@
+package interpeter
+
+type LoxPromise struct {
+	Stringifyable
+	settled bool
+	value   LoxValue
+	err     RuntimeError
+
+	// Whether anything waits on the promise, rejections nobody handles fail the program.
+	handled   bool
+	callbacks []func() RuntimeError
+}
+
+func NewLoxPromise() *LoxPromise {
+	return &LoxPromise{}
+}
+
+func (p *LoxPromise) isSettled() bool {
+	return p.settled
+}
+
+func (p *LoxPromise) settle(loop *eventLoop, value LoxValue, err RuntimeError) {
+	if p.settled {
+		return
+	}
+
+	p.settled, p.value, p.err = true, value, err
+	for _, callback := range p.callbacks {
+		loop.enqueue(callback)
+	}
+	p.callbacks = nil
+
+	if err != nil {
+		loop.rejected = append(loop.rejected, p)
+	}
+}
+
+// Runs the callback on the event loop once the promise is settled.
+func (p *LoxPromise) then(loop *eventLoop, callback func() RuntimeError) {
+	p.handled = true
+	if p.settled {
+		loop.enqueue(callback)
+	} else {
+		p.callbacks = append(p.callbacks, callback)
+	}
+}
+
+func (p *LoxPromise) ToString() string {
+	switch {
+	case !p.settled:
+		return "<promise pending>"
+	case p.err != nil:
+		return "<promise rejected>"
+	}
+	return "<promise resolved>"
+}
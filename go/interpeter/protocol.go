@@ -0,0 +1,130 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/scanner"
+	"strings"
+)
+
+// Methods that let instances take part in operators, printing, indexing and calls.
+const (
+	ADD_METHOD           = "__add"
+	SUBTRACT_METHOD      = "__sub"
+	MULTIPLY_METHOD      = "__mul"
+	DIVIDE_METHOD        = "__div"
+	MODULO_METHOD        = "__mod"
+	NEGATE_METHOD        = "__neg"
+	EQUAL_METHOD         = "__eq"
+	LESS_METHOD          = "__lt"
+	LESS_EQUAL_METHOD    = "__le"
+	GREATER_METHOD       = "__gt"
+	GREATER_EQUAL_METHOD = "__ge"
+	GET_METHOD           = "__get"
+	SET_METHOD           = "__set"
+	CALL_METHOD          = "__call"
+	TO_STRING_METHOD     = "toString"
+	ITERATOR_METHOD      = "iterator"
+	HAS_NEXT_METHOD      = "hasNext"
+	NEXT_METHOD          = "next"
+)
+
+var operatorMethods = map[scanner.TokenType]string{
+	scanner.PLUS:          ADD_METHOD,
+	scanner.MINUS:         SUBTRACT_METHOD,
+	scanner.STAR:          MULTIPLY_METHOD,
+	scanner.SLASH:         DIVIDE_METHOD,
+	scanner.PERCENT:       MODULO_METHOD,
+	scanner.EQUAL_EQUAL:   EQUAL_METHOD,
+	scanner.BANG_EQUAL:    EQUAL_METHOD,
+	scanner.LESS:          LESS_METHOD,
+	scanner.LESS_EQUAL:    LESS_EQUAL_METHOD,
+	scanner.GREATER:       GREATER_METHOD,
+	scanner.GREATER_EQUAL: GREATER_EQUAL_METHOD,
+}
+
+func (c *LoxInstance) method(name string) (*LoxFunction, bool) {
+	if method, hasMethod := c.Class.Methods[name]; hasMethod {
+		return method.Bind(c), true
+	}
+	return nil, false
+}
+
+// Looks up a protocol method on an instance value.
+func protocolMethod(value LoxValue, name string) (*LoxFunction, bool) {
+	if instance, isInstance := value.(*LoxInstance); isInstance {
+		return instance.method(name)
+	}
+	return nil, false
+}
+
+func (i *Interpreter) callMethod(method *LoxFunction, line int, args ...LoxValue) (LoxValue, RuntimeError) {
+	if len(args) != method.Arity() {
+		return nil, NewRuntimeError(line, fmt.Sprintf("Expected '%s' to take %d arguments but it takes %d.", method.declaration.Name.Lexeme, len(args), method.Arity()))
+	}
+	return method.Call(i, args)
+}
+
+// Dispatches a binary operator to the left operand's protocol method, if it has one.
+func (i *Interpreter) binaryMethod(operator scanner.Token, left LoxValue, right LoxValue) (LoxValue, bool, RuntimeError) {
+	name, hasName := operatorMethods[operator.Type]
+	if !hasName {
+		return nil, false, nil
+	}
+
+	method, hasMethod := protocolMethod(left, name)
+	if !hasMethod {
+		return nil, false, nil
+	}
+
+	value, err := i.callMethod(method, operator.Line, right)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if operator.Type == scanner.BANG_EQUAL {
+		return !isTruthy(value), true, nil
+	}
+	if operator.Type == scanner.EQUAL_EQUAL {
+		return isTruthy(value), true, nil
+	}
+	return value, true, nil
+}
+
+// Converts a value to the text print shows for it. Unlike the Stringify function, this calls a
+// user defined toString on instances, so it runs Lox code on the interpreter.
+func (i *Interpreter) Stringify(value LoxValue) (string, RuntimeError) {
+	return i.stringify(value)
+}
+
+// Converts a value to its string form, calling a user defined toString on instances.
+func (i *Interpreter) stringify(value LoxValue) (string, RuntimeError) {
+	switch v := value.(type) {
+	case *LoxInstance:
+		method, hasMethod := v.method(TO_STRING_METHOD)
+		if !hasMethod {
+			break
+		}
+
+		result, err := i.callMethod(method, i.currentFrame().Line)
+		if err != nil {
+			return "", err
+		}
+
+		if text, isString := result.(string); isString {
+			return text, nil
+		}
+		return i.stringify(result)
+	case *LoxList:
+		var elements []string
+		for _, element := range v.Elements {
+			text, err := i.stringify(element)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, text)
+		}
+		return "[" + strings.Join(elements, ", ") + "]", nil
+	}
+
+	return Stringify(value), nil
+}
@@ -0,0 +1,19 @@
+package interpeter
+
+import (
+	"fmt"
+)
+
+type LoxRange struct {
+	Stringifyable
+	Start int64
+	End   int64
+}
+
+func NewLoxRange(start int64, end int64) *LoxRange {
+	return &LoxRange{Start: start, End: end}
+}
+
+func (r *LoxRange) ToString() string {
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
@@ -14,6 +14,7 @@ const (
 	FUNCTION      FunctionType = "Function"
 	METHOD        FunctionType = "Method"
 	INITIALIZER   FunctionType = "Initializer"
+	LAMBDA        FunctionType = "Lambda"
 )
 
 type ClassType string
@@ -21,6 +22,7 @@ type ClassType string
 const (
 	NONE_CLASS ClassType = "None"
 	CLASS      ClassType = "Class"
+	STATIC     ClassType = "Static"
 )
 
 type Resolver struct {
@@ -28,16 +30,35 @@ type Resolver struct {
 	s statements.Visitor[LoxValue, RuntimeError]
 
 	scopes              []map[string]bool
+	constants           []map[string]bool
 	currentFunctionType FunctionType
 	currentClassType    ClassType
-	interpreter         *Interpreter
+	locals              map[expressions.Expression[LoxValue, RuntimeError]]int
+	symbols             *SymbolTable
+
+	// The trait declaration each name is bound to, nil for names bound to anything else. Classes
+	// look up the traits they use here to detect conflicts between them.
+	traits       []map[string]*statements.Trait[LoxValue, RuntimeError]
+	globalTraits map[string]*statements.Trait[LoxValue, RuntimeError]
+	warnings     []RuntimeError
 }
 
 func NewResolver(interpreter *Interpreter) *Resolver {
+	return NewResolverWithSymbols(interpreter, nil)
+}
+
+func NewResolverWithSymbols(interpreter *Interpreter, symbols *SymbolTable) *Resolver {
+	return newResolver(interpreter.locals, symbols)
+}
+
+// Creates a resolver that records the scope depth of each local variable in the given map.
+func newResolver(locals map[expressions.Expression[LoxValue, RuntimeError]]int, symbols *SymbolTable) *Resolver {
 	return &Resolver{
-		interpreter:         interpreter,
+		locals:              locals,
+		symbols:             symbols,
 		currentClassType:    NONE_CLASS,
 		currentFunctionType: NONE_FUNCTION,
+		globalTraits:        map[string]*statements.Trait[LoxValue, RuntimeError]{},
 	}
 }
 
@@ -74,10 +95,19 @@ func (r *Resolver) VisitPrintStatement(statement *statements.Print[LoxValue, Run
 }
 
 func (r *Resolver) VisitVarStatement(statement *statements.Var[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
-	if err := r.declare(statement.Name); err != nil {
+	kind := VARIABLE_SYMBOL
+	if statement.Constant {
+		kind = CONSTANT_SYMBOL
+	}
+
+	if err := r.declare(statement.Name, kind); err != nil {
 		return nil, err
 	}
 
+	if statement.Constant && r.hasScopes() {
+		r.constants[len(r.constants)-1][statement.Name.Lexeme] = true
+	}
+
 	if statement.Initializer != nil {
 		if err := r.resolveExpression(statement.Initializer); err != nil {
 			return nil, err
@@ -87,12 +117,180 @@ func (r *Resolver) VisitVarStatement(statement *statements.Var[LoxValue, Runtime
 	return nil, nil
 }
 
+func (r *Resolver) VisitYieldStatement(statement *statements.Yield[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if r.currentFunctionType == NONE_FUNCTION {
+		return nil, NewRuntimeError(statement.Keyword.Line, "Can't yield from top-level code.")
+	}
+
+	if r.currentFunctionType == INITIALIZER {
+		return nil, NewRuntimeError(statement.Keyword.Line, "Can't yield from an initializer.")
+	}
+
+	if statement.Value != nil {
+		if err := r.resolveExpression(statement.Value); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitForInStatement(statement *statements.ForIn[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if err := r.resolveExpression(statement.Iterable); err != nil {
+		return nil, err
+	}
+
+	r.beginScope()
+	if err := r.declare(statement.Name, VARIABLE_SYMBOL); err != nil {
+		return nil, err
+	}
+	r.define(statement.Name)
+
+	if err := r.resolveStatement(statement.Body); err != nil {
+		return nil, err
+	}
+	r.endScope()
+	return nil, nil
+}
+
+func (r *Resolver) VisitAwaitExpression(exp *expressions.Await[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return nil, r.resolveExpression(exp.Value)
+}
+
+func (r *Resolver) VisitRangeExpression(exp *expressions.Range[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if err := r.resolveExpression(exp.Start); err != nil {
+		return nil, err
+	}
+
+	return nil, r.resolveExpression(exp.End)
+}
+
+func (r *Resolver) VisitMatchStatement(statement *statements.Match[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if err := r.resolveExpression(statement.Value); err != nil {
+		return nil, err
+	}
+
+	hasWildcard := false
+	for _, matchCase := range statement.Cases {
+		if hasWildcard {
+			r.warn(matchCase.Keyword.Line, "Unreachable case after wildcard case.")
+		}
+
+		// The interpreter looks classes up before the bindings' scope exists.
+		if err := r.resolvePatternClasses(matchCase.Pattern); err != nil {
+			return nil, err
+		}
+
+		r.beginScope()
+		if err := r.resolveCasePattern(matchCase.Pattern); err != nil {
+			return nil, err
+		}
+
+		if err := r.resolveStatement(matchCase.Body); err != nil {
+			return nil, err
+		}
+		r.endScope()
+
+		hasWildcard = hasWildcard || matchCase.Pattern.IsIrrefutable()
+	}
+
+	if !hasWildcard {
+		r.warn(statement.Keyword.Line, "Match has no wildcard case, unmatched values are ignored.")
+	}
+	return nil, nil
+}
+
+func (r *Resolver) resolveCasePattern(pattern *statements.CasePattern[LoxValue, RuntimeError]) RuntimeError {
+	switch pattern.Kind {
+	case statements.BINDING_CASE:
+		if err := r.declare(pattern.Token, VARIABLE_SYMBOL); err != nil {
+			return err
+		}
+		r.define(pattern.Token)
+	case statements.ALTERNATIVE_CASE:
+		for _, alternative := range pattern.Patterns {
+			if bindsNames(alternative) {
+				return NewRuntimeError(alternative.Token.Line, "Can't bind names in alternative patterns.")
+			}
+		}
+	}
+
+	for _, subPattern := range pattern.Patterns {
+		if err := r.resolveCasePattern(subPattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) resolvePatternClasses(pattern *statements.CasePattern[LoxValue, RuntimeError]) RuntimeError {
+	if pattern.Kind == statements.CLASS_CASE {
+		if err := r.resolveExpression(pattern.Class); err != nil {
+			return err
+		}
+	}
+
+	for _, subPattern := range pattern.Patterns {
+		if err := r.resolvePatternClasses(subPattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindsNames(pattern *statements.CasePattern[LoxValue, RuntimeError]) bool {
+	if pattern.Kind == statements.BINDING_CASE {
+		return true
+	}
+
+	for _, subPattern := range pattern.Patterns {
+		if bindsNames(subPattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resolver) warn(line int, message string) {
+	r.warnings = append(r.warnings, NewRuntimeError(line, message))
+}
+
+func (r *Resolver) Warnings() []RuntimeError {
+	return r.warnings
+}
+
+func (r *Resolver) VisitDestructureStatement(statement *statements.Destructure[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	kind := VARIABLE_SYMBOL
+	if statement.Constant {
+		kind = CONSTANT_SYMBOL
+	}
+
+	for _, name := range statement.Pattern.Names {
+		if err := r.declare(name, kind); err != nil {
+			return nil, err
+		}
+
+		if statement.Constant && r.hasScopes() {
+			r.constants[len(r.constants)-1][name.Lexeme] = true
+		}
+	}
+
+	if err := r.resolveExpression(statement.Initializer); err != nil {
+		return nil, err
+	}
+
+	for _, name := range statement.Pattern.Names {
+		r.define(name)
+	}
+	return nil, nil
+}
+
 func (r *Resolver) VisitVariableExpression(exp *expressions.Variable[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	if r.hasScopes() && r.declaredInScope(exp.Name) && r.currentScope()[exp.Name.Lexeme] == false {
 		return nil, NewRuntimeError(exp.Name.Line, "Can't read local variable in its own initializer.")
 	}
 
 	r.resolveLocal(exp, exp.Name)
+	r.symbols.reference(exp.Name)
 	return nil, nil
 }
 
@@ -101,10 +299,51 @@ func (r *Resolver) VisitAssignmentExpression(exp *expressions.Assignment[LoxValu
 		return nil, err
 	}
 
+	if err := r.checkConstant(exp.Name); err != nil {
+		return nil, err
+	}
+
 	r.resolveLocal(exp, exp.Name)
+	r.symbols.reference(exp.Name)
 	return nil, nil
 }
 
+func (r *Resolver) VisitMultiAssignmentExpression(exp *expressions.MultiAssignment[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	for _, value := range exp.Values {
+		if err := r.resolveExpression(value); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, target := range exp.Targets {
+		if variable, isVariable := target.(*expressions.Variable[LoxValue, RuntimeError]); isVariable {
+			if err := r.checkConstant(variable.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := r.resolveExpression(target); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Assignments to global constants are only detected at runtime.
+func (r *Resolver) checkConstant(name scanner.Token) RuntimeError {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, declared := r.scopes[i][name.Lexeme]; declared {
+			if r.constants[i][name.Lexeme] {
+				return NewRuntimeError(name.Line, "Can't assign to constant '"+name.Lexeme+"'.")
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
 func (r *Resolver) VisitBlockStatement(statement *statements.Block[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
 	r.beginScope()
 	if err := r.Resolve(statement.Statements); err != nil {
@@ -128,7 +367,7 @@ func (r *Resolver) VisitIfStatement(statement *statements.If[LoxValue, RuntimeEr
 		return nil, nil
 	}
 
-	if err := r.resolveStatement(statement.IfBranch); err != nil {
+	if err := r.resolveStatement(statement.ElseBranch); err != nil {
 		return nil, err
 	}
 
@@ -174,7 +413,7 @@ func (r *Resolver) VisitCallExpression(exp *expressions.Call[LoxValue, RuntimeEr
 }
 
 func (r *Resolver) VisitFunctionStatement(statement *statements.Function[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
-	if err := r.declare(statement.Name); err != nil {
+	if err := r.declare(statement.Name, FUNCTION_SYMBOL); err != nil {
 		return nil, err
 	}
 	r.define(statement.Name)
@@ -185,10 +424,25 @@ func (r *Resolver) VisitClassStatement(statement *statements.Class[LoxValue, Run
 	enclosingClassType := r.currentClassType
 	r.currentClassType = CLASS
 
-	if err := r.declare(statement.Name); err != nil {
+	if err := r.declare(statement.Name, CLASS_SYMBOL); err != nil {
 		return nil, err
 	}
 	r.define(statement.Name)
+
+	if err := r.resolveTraits(statement); err != nil {
+		return nil, err
+	}
+
+	r.currentClassType = STATIC
+	for _, method := range statement.StaticMethods {
+		r.symbols.declareMember(statement.Name, method.Name, METHOD_SYMBOL)
+
+		if err := r.resolveFunction(method, METHOD); err != nil {
+			return nil, err
+		}
+	}
+	r.currentClassType = CLASS
+
 	r.beginScope()
 
 	r.currentScope()["this"] = true
@@ -199,6 +453,96 @@ func (r *Resolver) VisitClassStatement(statement *statements.Class[LoxValue, Run
 			declaration = INITIALIZER
 		}
 
+		r.symbols.declareMember(statement.Name, method.Name, METHOD_SYMBOL)
+
+		if err := r.resolveFunction(method, declaration); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, accessor := range append(append([]statements.Function[LoxValue, RuntimeError]{}, statement.Getters...), statement.Setters...) {
+		r.symbols.declareMember(statement.Name, accessor.Name, METHOD_SYMBOL)
+
+		if err := r.resolveFunction(accessor, METHOD); err != nil {
+			return nil, err
+		}
+	}
+
+	r.endScope()
+	r.currentClassType = enclosingClassType
+	return nil, nil
+}
+
+func (r *Resolver) resolveTraits(statement *statements.Class[LoxValue, RuntimeError]) RuntimeError {
+	composition := newTraitComposition(statement)
+	for _, trait := range statement.Traits {
+		if trait.Name.Lexeme == statement.Name.Lexeme {
+			return NewRuntimeError(trait.Name.Line, "A class can't use itself as a trait.")
+		}
+
+		if _, err := r.VisitVariableExpression(trait); err != nil {
+			return err
+		}
+
+		// Traits that aren't known here, like parameters, are checked by the interpreter.
+		declaration := r.lookupTrait(trait.Name)
+		if declaration == nil {
+			continue
+		}
+
+		var methods []string
+		for _, method := range declaration.Methods {
+			methods = append(methods, method.Name.Lexeme)
+		}
+		if _, err := composition.add(trait.Name.Line, declaration, trait.Name.Lexeme, methods); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Records the trait a name is bound to, or nil when it is bound to something else.
+func (r *Resolver) bindTrait(name scanner.Token, trait *statements.Trait[LoxValue, RuntimeError]) {
+	if r.hasScopes() {
+		r.traits[len(r.traits)-1][name.Lexeme] = trait
+	} else {
+		r.globalTraits[name.Lexeme] = trait
+	}
+}
+
+func (r *Resolver) lookupTrait(name scanner.Token) *statements.Trait[LoxValue, RuntimeError] {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, declared := r.scopes[i][name.Lexeme]; declared {
+			return r.traits[i][name.Lexeme]
+		}
+	}
+
+	return r.globalTraits[name.Lexeme]
+}
+
+func (r *Resolver) VisitTraitStatement(statement *statements.Trait[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	enclosingClassType := r.currentClassType
+	r.currentClassType = CLASS
+
+	if err := r.declare(statement.Name, TRAIT_SYMBOL); err != nil {
+		return nil, err
+	}
+	r.define(statement.Name)
+
+	r.bindTrait(statement.Name, statement)
+
+	r.beginScope()
+	r.currentScope()["this"] = true
+
+	for _, method := range statement.Methods {
+		declaration := METHOD
+		if method.Name.Lexeme == "init" {
+			declaration = INITIALIZER
+		}
+
+		r.symbols.declareMember(statement.Name, method.Name, METHOD_SYMBOL)
+
 		if err := r.resolveFunction(method, declaration); err != nil {
 			return nil, err
 		}
@@ -225,9 +569,63 @@ func (r *Resolver) VisitSetExpression(exp *expressions.Set[LoxValue, RuntimeErro
 	return nil, nil
 }
 
+func (r *Resolver) VisitListExpression(exp *expressions.List[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	for _, element := range exp.Elements {
+		if err := r.resolveExpression(element); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *Resolver) VisitIndexExpression(exp *expressions.Index[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if err := r.resolveExpression(exp.Object); err != nil {
+		return nil, err
+	}
+
+	return nil, r.resolveExpression(exp.Index)
+}
+
+func (r *Resolver) VisitSetIndexExpression(exp *expressions.SetIndex[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if err := r.resolveExpression(exp.Object); err != nil {
+		return nil, err
+	}
+
+	if err := r.resolveExpression(exp.Index); err != nil {
+		return nil, err
+	}
+
+	return nil, r.resolveExpression(exp.Value)
+}
+
+func (r *Resolver) VisitConditionalExpression(exp *expressions.Conditional[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	if err := r.resolveExpression(exp.Condition); err != nil {
+		return nil, err
+	}
+
+	if err := r.resolveExpression(exp.ThenBranch); err != nil {
+		return nil, err
+	}
+
+	return nil, r.resolveExpression(exp.ElseBranch)
+}
+
+func (r *Resolver) VisitOptionalChainExpression(exp *expressions.OptionalChain[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	return nil, r.resolveExpression(exp.Chain)
+}
+
+func (r *Resolver) VisitLambdaExpression(exp *expressions.Lambda[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
+	function := exp.Function.(*statements.Function[LoxValue, RuntimeError])
+	return nil, r.resolveFunction(*function, LAMBDA)
+}
+
 func (r *Resolver) VisitThisExpression(exp *expressions.This[LoxValue, RuntimeError]) (LoxValue, RuntimeError) {
-	if r.currentFunctionType == NONE_FUNCTION {
+	switch r.currentClassType {
+	case NONE_CLASS:
 		return nil, NewRuntimeError(exp.Keyword.Line, "Can't use 'this' outside of a class.")
+	case STATIC:
+		return nil, NewRuntimeError(exp.Keyword.Line, "Can't use 'this' in a static method.")
 	}
 
 	r.resolveLocal(exp, exp.Keyword)
@@ -262,7 +660,7 @@ func (r *Resolver) resolveFunction(statement statements.Function[LoxValue, Runti
 	r.beginScope()
 
 	for _, param := range statement.Params {
-		if err := r.declare(param); err != nil {
+		if err := r.declare(param, PARAMETER_SYMBOL); err != nil {
 			return err
 		}
 
@@ -286,7 +684,7 @@ func (r *Resolver) resolveStatement(statement statements.Statement[LoxValue, Run
 func (r *Resolver) resolveLocal(expression expressions.Expression[LoxValue, RuntimeError], name scanner.Token) {
 	for i := len(r.scopes) - 1; i >= 0; i-- {
 		if _, hasValue := r.scopes[i][name.Lexeme]; hasValue {
-			r.interpreter.resolve(expression, len(r.scopes)-i-1)
+			r.locals[expression] = len(r.scopes) - i - 1
 			return
 		}
 	}
@@ -315,11 +713,18 @@ func (r *Resolver) declaredInScope(variable scanner.Token) bool {
 	return ok
 }
 
-func (r *Resolver) declare(name scanner.Token) RuntimeError {
+func (r *Resolver) declare(name scanner.Token, kind SymbolKind) RuntimeError {
+	r.symbols.declare(name, kind)
+	r.bindTrait(name, nil)
+
 	if !r.hasScopes() {
 		return nil
 	}
 
+	if r.constants[len(r.constants)-1][name.Lexeme] {
+		return NewRuntimeError(name.Line, "Can't redeclare constant '"+name.Lexeme+"'.")
+	}
+
 	if r.declaredInScope(name) {
 		return NewRuntimeError(name.Line, fmt.Sprintf("Already a variable '%s' in this scope.", name.Lexeme))
 	}
@@ -338,8 +743,14 @@ func (r *Resolver) define(name scanner.Token) {
 
 func (r *Resolver) endScope() {
 	r.scopes = r.scopes[:len(r.scopes)-1]
+	r.constants = r.constants[:len(r.constants)-1]
+	r.traits = r.traits[:len(r.traits)-1]
+	r.symbols.endScope()
 }
 
 func (r *Resolver) beginScope() {
 	r.scopes = append(r.scopes, map[string]bool{})
+	r.constants = append(r.constants, map[string]bool{})
+	r.traits = append(r.traits, map[string]*statements.Trait[LoxValue, RuntimeError]{})
+	r.symbols.beginScope()
 }
@@ -0,0 +1,147 @@
+package interpeter
+
+import "github.com/lukas-reining/lox/scanner"
+
+type SymbolKind string
+
+const (
+	VARIABLE_SYMBOL  SymbolKind = "variable"
+	CONSTANT_SYMBOL  SymbolKind = "constant"
+	PARAMETER_SYMBOL SymbolKind = "parameter"
+	FUNCTION_SYMBOL  SymbolKind = "function"
+	CLASS_SYMBOL     SymbolKind = "class"
+	TRAIT_SYMBOL     SymbolKind = "trait"
+	METHOD_SYMBOL    SymbolKind = "method"
+)
+
+type Symbol struct {
+	Name       scanner.Token
+	Kind       SymbolKind
+	Global     bool
+	References []scanner.Token
+	Members    []*Symbol
+}
+
+// SymbolTable records the declarations the resolver sees and which of them
+// each variable reference resolves to. All methods accept a nil table so the
+// resolver can call them unconditionally.
+type SymbolTable struct {
+	Symbols []*Symbol
+
+	scopes         []map[string]*Symbol
+	globals        map[string]*Symbol
+	pendingGlobals []scanner.Token
+	declarations   map[scanner.Token]*Symbol
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		globals:      map[string]*Symbol{},
+		declarations: map[scanner.Token]*Symbol{},
+	}
+}
+
+func (t *SymbolTable) beginScope() {
+	if t == nil {
+		return
+	}
+
+	t.scopes = append(t.scopes, map[string]*Symbol{})
+}
+
+func (t *SymbolTable) endScope() {
+	if t == nil {
+		return
+	}
+
+	t.scopes = t.scopes[:len(t.scopes)-1]
+}
+
+func (t *SymbolTable) declare(name scanner.Token, kind SymbolKind) {
+	if t == nil {
+		return
+	}
+
+	symbol := &Symbol{Name: name, Kind: kind, Global: len(t.scopes) == 0}
+	t.Symbols = append(t.Symbols, symbol)
+	t.declarations[name] = symbol
+
+	if symbol.Global {
+		t.globals[name.Lexeme] = symbol
+		t.linkPendingGlobals(symbol)
+	} else {
+		t.scopes[len(t.scopes)-1][name.Lexeme] = symbol
+	}
+}
+
+func (t *SymbolTable) declareMember(owner scanner.Token, name scanner.Token, kind SymbolKind) {
+	if t == nil {
+		return
+	}
+
+	symbol := &Symbol{Name: name, Kind: kind}
+	t.Symbols = append(t.Symbols, symbol)
+	t.declarations[name] = symbol
+
+	if ownerSymbol, ok := t.declarations[owner]; ok {
+		ownerSymbol.Members = append(ownerSymbol.Members, symbol)
+	}
+}
+
+func (t *SymbolTable) reference(name scanner.Token) {
+	if t == nil {
+		return
+	}
+
+	for i := len(t.scopes) - 1; i >= 0; i-- {
+		if symbol, ok := t.scopes[i][name.Lexeme]; ok {
+			symbol.References = append(symbol.References, name)
+			return
+		}
+	}
+
+	if symbol, ok := t.globals[name.Lexeme]; ok {
+		symbol.References = append(symbol.References, name)
+	} else {
+		// Globals are late bound, so a function may refer to one declared further down.
+		t.pendingGlobals = append(t.pendingGlobals, name)
+	}
+}
+
+func (t *SymbolTable) linkPendingGlobals(symbol *Symbol) {
+	var pending []scanner.Token
+	for _, name := range t.pendingGlobals {
+		if name.Lexeme == symbol.Name.Lexeme {
+			symbol.References = append(symbol.References, name)
+		} else {
+			pending = append(pending, name)
+		}
+	}
+	t.pendingGlobals = pending
+}
+
+func (t *SymbolTable) Globals() []*Symbol {
+	var globals []*Symbol
+	for _, symbol := range t.Symbols {
+		if symbol.Global {
+			globals = append(globals, symbol)
+		}
+	}
+	return globals
+}
+
+func (t *SymbolTable) Lookup(token scanner.Token) *Symbol {
+	for _, symbol := range t.Symbols {
+		if symbol.Name == token {
+			return symbol
+		}
+
+		for _, reference := range symbol.References {
+			if reference == token {
+				return symbol
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package interpeter
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/parser/statements"
+	"sort"
+)
+
+type LoxTrait struct {
+	Stringifyable
+	Name    string
+	Methods map[string]LoxFunction
+}
+
+func NewLoxTrait(name string, methods map[string]LoxFunction) *LoxTrait {
+	return &LoxTrait{
+		Name:    name,
+		Methods: methods,
+	}
+}
+
+// Returns the names of the trait's methods in a stable order.
+func (t *LoxTrait) methodNames() []string {
+	names := make([]string, 0, len(t.Methods))
+	for name := range t.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *LoxTrait) ToString() string {
+	return "<trait " + t.Name + ">"
+}
+
+// Decides which methods a class takes from its traits. The resolver and the interpreter both
+// use it, the resolver for the traits it can see and the interpreter for the ones it can't.
+type traitComposition struct {
+	// Names the class declares itself, as a method, getter, setter or static method.
+	members map[string]bool
+	// The trait each method was taken from, by identity.
+	providers map[string]traitProvider
+}
+
+type traitProvider struct {
+	trait any
+	name  string
+}
+
+func newTraitComposition(statement *statements.Class[LoxValue, RuntimeError]) *traitComposition {
+	members := map[string]bool{}
+	for _, kind := range [][]statements.Function[LoxValue, RuntimeError]{statement.Methods, statement.Getters, statement.Setters, statement.StaticMethods} {
+		for _, member := range kind {
+			members[member.Name.Lexeme] = true
+		}
+	}
+
+	return &traitComposition{members: members, providers: map[string]traitProvider{}}
+}
+
+// Returns the methods the class takes from the trait, which are the ones it doesn't declare
+// itself. Two different traits providing the same of those is an error.
+func (c *traitComposition) add(line int, trait any, name string, methods []string) ([]string, RuntimeError) {
+	var taken []string
+	for _, method := range methods {
+		if c.members[method] {
+			continue
+		}
+
+		if provider, provided := c.providers[method]; provided {
+			if provider.trait == trait {
+				continue
+			}
+			return nil, NewRuntimeError(line, fmt.Sprintf("Method '%s' is provided by both trait '%s' and trait '%s'.", method, provider.name, name))
+		}
+
+		c.providers[method] = traitProvider{trait: trait, name: name}
+		taken = append(taken, method)
+	}
+	return taken, nil
+}
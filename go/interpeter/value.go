@@ -1,11 +1,18 @@
 package interpeter
 
+import "github.com/lukas-reining/lox/scanner"
+
 type LoxValue = any
 
 type Stringifyable interface {
 	ToString() string
 }
 
+// Native values such as generators and fibers that expose properties to scripts.
+type nativeObject interface {
+	get(name scanner.Token) (LoxValue, RuntimeError)
+}
+
 type ReturnValue struct {
 	Value LoxValue
 }
@@ -0,0 +1,73 @@
+package lox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"os"
+	"path/filepath"
+)
+
+// A ProgramCache stores compiled programs on disk, keyed by a hash of their source, so that
+// running an unchanged script again skips scanning, parsing and resolving.
+type ProgramCache struct {
+	dir string
+}
+
+func NewProgramCache(dir string) *ProgramCache {
+	return &ProgramCache{dir: dir}
+}
+
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "glox"), nil
+}
+
+func (c *ProgramCache) path(source string, optimized bool) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%t\x00%s", interpeter.PROGRAM_FORMAT_VERSION, optimized, source)))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".loxc")
+}
+
+// Returns the cached program for the source, if there is a readable one.
+func (c *ProgramCache) Load(source string, optimized bool) (*interpeter.Program, bool) {
+	file, err := os.Open(c.path(source, optimized))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	program, err := interpeter.DecodeProgram(file)
+	if err != nil {
+		return nil, false
+	}
+	return program, true
+}
+
+func (c *ProgramCache) Store(source string, optimized bool, program *interpeter.Program) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	// Written to a temporary file first so that concurrent runs never read a partial program.
+	file, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := program.Encode(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), c.path(source, optimized))
+}
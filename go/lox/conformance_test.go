@@ -0,0 +1,83 @@
+package lox
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected output of the conformance scripts")
+
+// How a conformance script is run. Every mode has to produce the script's expected output.
+type conformanceMode struct {
+	name     string
+	optimize bool
+	cache    bool
+}
+
+var conformanceModes = []conformanceMode{
+	{name: "plain"},
+	{name: "optimize", optimize: true},
+	{name: "cache", cache: true},
+	{name: "cache-optimize", cache: true, optimize: true},
+}
+
+func runScript(t *testing.T, path string, mode conformanceMode, cacheDir string) string {
+	t.Helper()
+
+	var output bytes.Buffer
+	engine := NewLoxWithOutput(&output, &output)
+	engine.SetOptimize(mode.optimize)
+	if mode.cache {
+		engine.SetCache(NewProgramCache(cacheDir))
+	}
+
+	_ = engine.RunFile(path)
+	return output.String()
+}
+
+// Runs every script in testdata with and without the optimizer and the program cache, and
+// compares the output with the script's .out file.
+func TestConformance(t *testing.T) {
+	scripts, err := filepath.Glob(filepath.Join("testdata", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, script := range scripts {
+		expectedPath := strings.TrimSuffix(script, ".lox") + ".out"
+
+		if *update {
+			output := runScript(t, script, conformanceModes[0], "")
+			if err := os.WriteFile(expectedPath, []byte(output), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		expected, err := os.ReadFile(expectedPath)
+		if err != nil {
+			t.Fatalf("%s has no expected output, run the tests with -update: %v", script, err)
+		}
+
+		for _, mode := range conformanceModes {
+			t.Run(filepath.Base(script)+"/"+mode.name, func(t *testing.T) {
+				cacheDir := t.TempDir()
+
+				// Cached modes run twice, the second run loads the program from the cache.
+				runs := 1
+				if mode.cache {
+					runs = 2
+				}
+
+				for run := 0; run < runs; run++ {
+					if output := runScript(t, script, mode, cacheDir); output != string(expected) {
+						t.Errorf("run %d printed\n%s\nbut expected\n%s", run+1, output, expected)
+					}
+				}
+			})
+		}
+	}
+}
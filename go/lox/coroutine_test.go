@@ -0,0 +1,38 @@
+package lox
+
+import (
+	"io"
+	"runtime"
+	"testing"
+)
+
+// Generators and fibers that are still suspended when the program finishes
+// must not keep their goroutines parked.
+func TestRunCancelsSuspendedCoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	engine := NewLoxWithOutput(io.Discard, io.Discard)
+	script := `
+fun numbers() {
+  yield 1;
+  yield 2;
+}
+
+var generators = [];
+for (var i in 0..100) {
+  var generator = numbers();
+  generator.next();
+  push(generators, generator);
+}
+
+var fiber = Fiber(fun() { Fiber.yield(1); });
+fiber.resume();
+`
+	if _, _, err := engine.Run(script, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines are still running after the program finished, %d were before", after, before)
+	}
+}
@@ -0,0 +1,68 @@
+package lox
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser"
+	"github.com/lukas-reining/lox/scanner"
+	"io"
+	"log"
+	"os"
+)
+
+type DumpMode string
+
+const (
+	DUMP_TOKENS DumpMode = "tokens"
+	DUMP_AST    DumpMode = "ast"
+)
+
+func (l *Lox) DumpFile(filePath string, mode DumpMode, asJson bool) error {
+	dat, err := os.ReadFile(filePath)
+
+	if err != nil {
+		log.Fatalf("File not found: %s", filePath)
+	}
+
+	err = l.Dump(string(dat), mode, asJson, os.Stdout)
+
+	if err != nil {
+		l.error(err)
+	}
+
+	return err
+}
+
+func (l *Lox) Dump(script string, mode DumpMode, asJson bool, out io.Writer) error {
+	sourceScanner := scanner.NewScanner(script)
+	tokens, err := sourceScanner.ScanTokens()
+	if err != nil {
+		return err
+	}
+
+	if mode == DUMP_TOKENS {
+		if asJson {
+			return writeJson(out, tokens)
+		}
+
+		for _, token := range tokens {
+			_, _ = fmt.Fprintf(out, "%d:%d %s\n", token.Line, token.Column, token.ToString())
+		}
+		return nil
+	}
+
+	sourceParser := parser.NewParser[any, interpeter.RuntimeError](tokens)
+	statements, parseErr := sourceParser.Parse()
+	if parseErr != nil {
+		return parseErr
+	}
+
+	return writeJson(out, interpeter.NewJsonPrinter().Print(statements))
+}
+
+func writeJson(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
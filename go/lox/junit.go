@@ -0,0 +1,125 @@
+package lox
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Stack   string `xml:",chardata"`
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// Writes the results as a JUnit XML report with one test suite per file.
+func WriteJUnit(w io.Writer, results []TestResult) error {
+	report := junitTestSuites{}
+	suites := map[string]int{}
+	var total time.Duration
+
+	for _, result := range results {
+		index, ok := suites[result.File]
+		if !ok {
+			index = len(report.Suites)
+			suites[result.File] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: result.File})
+		}
+		suite := &report.Suites[index]
+
+		name := result.Name
+		if name == "" {
+			name = result.File
+		}
+		testCase := junitTestCase{
+			Name:      name,
+			ClassName: result.File,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: result.Output,
+		}
+
+		var stack strings.Builder
+		for _, frame := range result.Stack {
+			_, _ = fmt.Fprintf(&stack, "at %s (%s:%d)\n", frame.Function, result.File, frame.Line)
+		}
+		problem := &junitProblem{Message: result.Message, Stack: stack.String()}
+
+		switch result.Status {
+		case TEST_FAILED:
+			problem.Type = "AssertionError"
+			testCase.Failure = problem
+			suite.Failures++
+		case TEST_ERROR:
+			problem.Type = "RuntimeError"
+			if result.Name == "" {
+				problem.Type = "CompileError"
+			}
+			testCase.Error = problem
+			suite.Errors++
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+		total += result.Duration
+	}
+
+	for index := range report.Suites {
+		suite := &report.Suites[index]
+		var duration time.Duration
+		for _, result := range results {
+			if result.File == suite.Name {
+				duration += result.Duration
+			}
+		}
+		suite.Time = junitSeconds(duration)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+	}
+	report.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -6,19 +6,47 @@ import (
 	"github.com/lukas-reining/lox/interpeter"
 	"github.com/lukas-reining/lox/parser"
 	"github.com/lukas-reining/lox/scanner"
+	"io"
 	"log"
 	"os"
 )
 
 type Lox struct {
+	hooks  []interpeter.Hook
+	out    io.Writer
+	errOut io.Writer
+	cache  *ProgramCache
+	// Whether compiled programs are run through the optimizer.
+	optimize bool
 }
 
 func NewLox() *Lox {
-	return &Lox{}
+	return NewLoxWithOutput(os.Stdout, os.Stderr)
+}
+
+// Creates an engine whose scripts print to out and whose errors are reported to errOut.
+func NewLoxWithOutput(out io.Writer, errOut io.Writer) *Lox {
+	return &Lox{out: out, errOut: errOut}
+}
+
+func (l *Lox) AddHook(hook interpeter.Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+func (l *Lox) SetCache(cache *ProgramCache) {
+	l.cache = cache
+}
+
+func (l *Lox) SetOptimize(optimize bool) {
+	l.optimize = optimize
 }
 
 func (l *Lox) report(line int, where string, messsage string) {
-	_, _ = fmt.Fprintf(os.Stderr, "[line %d] Error%s: %s\n", line, where, messsage)
+	_, _ = fmt.Fprintf(l.errOut, "[line %d] Error%s: %s\n", line, where, messsage)
+}
+
+func (l *Lox) warning(warning interpeter.RuntimeError) {
+	_, _ = fmt.Fprintf(l.errOut, "[line %d] Warning: %s\n", warning.Line(), warning.Message())
 }
 
 func (l *Lox) scannerError(err scanner.ScannerError) {
@@ -77,37 +105,106 @@ func (l *Lox) RunPrompt() {
 		text, err := reader.ReadString('\n')
 
 		if err == nil {
-			value, env, err := l.Run(text, currentEnv)
+			result, env, err := l.runLine(text, currentEnv)
 
 			if err != nil {
 				l.error(err)
 			} else {
 				currentEnv = env
-				println(interpeter.Stringify(value))
+				println(result)
 			}
 		}
 	}
 }
 
-func (l *Lox) Run(script string, env *interpeter.Environment) (interpeter.LoxValue, *interpeter.Environment, error) {
+// Runs a line of the prompt and returns its value the way print shows it, calling a user
+// defined toString on the interpreter that ran the line.
+func (l *Lox) runLine(line string, env *interpeter.Environment) (string, *interpeter.Environment, error) {
+	program, err := l.Compile(line)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, warning := range program.Warnings() {
+		l.warning(warning)
+	}
+
+	interpreter := l.newInterpreter(env, l.out)
+	value, resultEnv, runErr := interpreter.Run(program)
+	if runErr != nil {
+		return "", nil, runErr
+	}
+
+	text, runErr := interpreter.Stringify(value)
+	if runErr != nil {
+		return "", nil, runErr
+	}
+	return text, resultEnv, nil
+}
+
+func (l *Lox) Compile(script string) (*interpeter.Program, error) {
+	if l.cache != nil {
+		if program, ok := l.cache.Load(script, l.optimize); ok {
+			return program, nil
+		}
+	}
+
 	sourceScanner := scanner.NewScanner(script)
 	tokens, err := sourceScanner.ScanTokens()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	sourceParser := parser.NewParser[any, interpeter.RuntimeError](tokens)
 	statements, err := sourceParser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := interpeter.Compile(statements, l.optimize)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.cache != nil {
+		// The cache only saves work, a program that can't be stored is still run.
+		_ = l.cache.Store(script, l.optimize, program)
+	}
+
+	return program, nil
+}
+
+func (l *Lox) Run(script string, env *interpeter.Environment) (interpeter.LoxValue, *interpeter.Environment, error) {
+	program, err := l.Compile(script)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	for _, warning := range program.Warnings() {
+		l.warning(warning)
+	}
+
+	return l.RunProgram(program, env)
+}
+
+// Creates an interpreter with the engine's hooks that prints to out.
+func (l *Lox) newInterpreter(env *interpeter.Environment, out io.Writer) *interpeter.Interpreter {
 	interpreter := interpeter.NewInterpreterWithEnv(env)
+	interpreter.SetOutput(out)
+	for _, hook := range l.hooks {
+		interpreter.AddHook(hook)
+	}
+	return &interpreter
+}
 
-	resolver := interpeter.NewResolver(&interpreter)
-	if err := resolver.Resolve(statements); err != nil {
+func (l *Lox) RunProgram(program *interpeter.Program, env *interpeter.Environment) (interpeter.LoxValue, *interpeter.Environment, error) {
+	interpreter := l.newInterpreter(env, l.out)
+	defer interpreter.Close()
+
+	value, resultEnv, err := interpreter.Run(program)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	return interpreter.Interpret(statements)
+	return value, resultEnv, nil
 }
@@ -0,0 +1,97 @@
+package lox
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+type ScriptResult struct {
+	Path   string
+	Output string
+	Err    error
+}
+
+// A script that is compiled at most once, no matter how often it appears in a batch.
+type compiledScript struct {
+	once    sync.Once
+	program *interpeter.Program
+	err     error
+}
+
+// Runs every script on its own interpreter using a pool of workers. Scripts listed more than
+// once share a single compiled program. The output and errors of each script are captured
+// separately and returned in the order of the given paths.
+func (l *Lox) RunParallel(paths []string, workers int) []ScriptResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	scripts := map[string]*compiledScript{}
+	for _, path := range paths {
+		if _, ok := scripts[path]; !ok {
+			scripts[path] = &compiledScript{}
+		}
+	}
+
+	results := make([]ScriptResult, len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = l.runCaptured(paths[index], scripts[paths[index]])
+			}
+		}()
+	}
+
+	for index := range paths {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (l *Lox) runCaptured(path string, script *compiledScript) ScriptResult {
+	var output bytes.Buffer
+	engine := NewLoxWithOutput(&output, &output)
+	engine.SetCache(l.cache)
+	engine.SetOptimize(l.optimize)
+
+	script.once.Do(func() {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			script.err = err
+			return
+		}
+		script.program, script.err = engine.Compile(string(source))
+	})
+
+	if script.err != nil {
+		if _, ok := script.err.(*fs.PathError); ok {
+			_, _ = fmt.Fprintf(&output, "File not found: %s\n", path)
+		} else {
+			engine.error(script.err)
+		}
+		return ScriptResult{Path: path, Output: output.String(), Err: script.err}
+	}
+
+	for _, warning := range script.program.Warnings() {
+		engine.warning(warning)
+	}
+
+	_, _, err := engine.RunProgram(script.program, nil)
+	if err != nil {
+		engine.error(err)
+	}
+
+	return ScriptResult{Path: path, Output: output.String(), Err: err}
+}
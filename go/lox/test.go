@@ -0,0 +1,222 @@
+package lox
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser/statements"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	TEST_FILE_SUFFIX     = "_test.lox"
+	TEST_FUNCTION_PREFIX = "test"
+)
+
+type TestStatus string
+
+const (
+	TEST_PASSED TestStatus = "PASS"
+	// An assertion did not hold.
+	TEST_FAILED TestStatus = "FAIL"
+	// Any other runtime error, or a file that doesn't compile.
+	TEST_ERROR TestStatus = "ERROR"
+)
+
+type StackFrame struct {
+	Function string
+	Line     int
+}
+
+type TestResult struct {
+	File string
+	// Empty for a file that couldn't be compiled.
+	Name     string
+	Status   TestStatus
+	Message  string
+	Stack    []StackFrame
+	Output   string
+	Duration time.Duration
+}
+
+// Returns the test files among the paths, searching directories recursively.
+func FindTestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Files that are named explicitly are run whatever their name.
+			if file == path && !entry.IsDir() || !entry.IsDir() && strings.HasSuffix(file, TEST_FILE_SUFFIX) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Returns the names of the top level functions that are tests, in the order they are declared.
+func testFunctions(program *interpeter.Program) []string {
+	var names []string
+	for _, statement := range program.Statements() {
+		function, ok := statement.(*statements.Function[interpeter.LoxValue, interpeter.RuntimeError])
+		if ok && strings.HasPrefix(function.Name.Lexeme, TEST_FUNCTION_PREFIX) {
+			names = append(names, function.Name.Lexeme)
+		}
+	}
+	return names
+}
+
+// Compiles the file once and runs each of its tests on a fresh interpreter, which first runs the
+// top level of the file and then calls the test function.
+func (l *Lox) RunTestFile(path string) []TestResult {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return []TestResult{{File: path, Status: TEST_ERROR, Message: fmt.Sprintf("File not found: %s", path)}}
+	}
+
+	program, err := l.Compile(string(source))
+	if err != nil {
+		var message bytes.Buffer
+		NewLoxWithOutput(&message, &message).error(err)
+		return []TestResult{{File: path, Status: TEST_ERROR, Message: strings.TrimSpace(message.String())}}
+	}
+
+	for _, warning := range program.Warnings() {
+		l.warning(warning)
+	}
+
+	var results []TestResult
+	for _, name := range testFunctions(program) {
+		results = append(results, l.runTest(path, program, name))
+	}
+	return results
+}
+
+func (l *Lox) runTest(path string, program *interpeter.Program, name string) TestResult {
+	var output bytes.Buffer
+	interpreter := l.newInterpreter(nil, &output)
+	defer interpreter.Close()
+	stack := newStackRecorder()
+	interpreter.AddHook(stack)
+
+	started := time.Now()
+	_, _, err := interpreter.Run(program)
+	if err == nil {
+		_, err = interpreter.CallGlobal(name)
+	}
+
+	result := TestResult{File: path, Name: name, Status: TEST_PASSED, Duration: time.Since(started)}
+	if err != nil {
+		result.Status = TEST_ERROR
+		if _, ok := err.(*interpeter.AssertionError); ok {
+			result.Status = TEST_FAILED
+		}
+		result.Message = fmt.Sprintf("[line %d] %s", err.Line(), err.Message())
+		result.Stack = stack.trace(interpreter, err)
+	}
+	result.Output = output.String()
+	return result
+}
+
+// The stackRecorder is a hook that keeps the line each call frame is executing, and takes a
+// snapshot of the call stack when an error starts unwinding it.
+type stackRecorder struct {
+	interpeter.BaseHook
+
+	lines map[*interpeter.Interpreter][]int
+	err   interpeter.RuntimeError
+	stack []StackFrame
+}
+
+func newStackRecorder() *stackRecorder {
+	return &stackRecorder{lines: map[*interpeter.Interpreter][]int{}}
+}
+
+func (r *stackRecorder) BeforeStatement(interpreter *interpeter.Interpreter, statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) interpeter.RuntimeError {
+	depth := len(interpreter.Frames())
+	lines := r.lines[interpreter]
+	for len(lines) < depth {
+		lines = append(lines, 0)
+	}
+	lines[depth-1] = statement.Line()
+	r.lines[interpreter] = lines[:depth]
+	return nil
+}
+
+func (r *stackRecorder) ExitFunction(interpreter *interpeter.Interpreter, frame *interpeter.CallFrame, value interpeter.LoxValue, err interpeter.RuntimeError) {
+	// The innermost frame an error leaves is the one it was raised in. Errors that are caught,
+	// like the ones assertThrows expects, are replaced by the next one.
+	if err != nil && err != r.err {
+		r.err = err
+		r.stack = r.snapshot(interpreter)
+	}
+}
+
+func (r *stackRecorder) snapshot(interpreter *interpeter.Interpreter) []StackFrame {
+	frames := interpreter.Frames()
+	lines := r.lines[interpreter]
+
+	// Tests are called by the runner, not from the top level of the script.
+	outermost := 0
+	if len(frames) > 1 {
+		outermost = 1
+	}
+
+	stack := make([]StackFrame, 0, len(frames))
+	for index := len(frames) - 1; index >= outermost; index-- {
+		frame := StackFrame{Function: frames[index].Name, Line: frames[index].Line}
+		if index < len(lines) && lines[index] != 0 {
+			frame.Line = lines[index]
+		}
+		stack = append(stack, frame)
+	}
+	return stack
+}
+
+// Returns the call stack, innermost frame first, at the point where the error was raised.
+func (r *stackRecorder) trace(interpreter *interpeter.Interpreter, err interpeter.RuntimeError) []StackFrame {
+	if err == r.err {
+		return r.stack
+	}
+	// Errors raised outside of any function never leave a frame.
+	return r.snapshot(interpreter)
+}
+
+func (r TestResult) String() string {
+	name := r.File
+	if r.Name != "" {
+		name += " " + r.Name
+	}
+
+	var text strings.Builder
+	_, _ = fmt.Fprintf(&text, "%-5s %s (%.3fms)\n", r.Status, name, float64(r.Duration)/float64(time.Millisecond))
+	if r.Status == TEST_PASSED {
+		return text.String()
+	}
+
+	for _, line := range strings.Split(r.Message, "\n") {
+		_, _ = fmt.Fprintf(&text, "    %s\n", line)
+	}
+	for _, frame := range r.Stack {
+		_, _ = fmt.Fprintf(&text, "        at %s (%s:%d)\n", frame.Function, r.File, frame.Line)
+	}
+
+	// What the test printed before it failed.
+	if r.Output != "" {
+		_, _ = fmt.Fprintf(&text, "    output:\n")
+		for _, line := range strings.Split(strings.TrimSuffix(r.Output, "\n"), "\n") {
+			_, _ = fmt.Fprintf(&text, "        %s\n", line)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,43 @@
+package lox
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTestFile(t *testing.T) {
+	engine := NewLoxWithOutput(io.Discard, io.Discard)
+	results := engine.RunTestFile(filepath.Join("testdata", "runner", "assertions_test.lox"))
+
+	expected := []struct {
+		name    string
+		status  TestStatus
+		message string
+	}{
+		{"testPasses", TEST_PASSED, ""},
+		{"testReportsExpectedFirst", TEST_FAILED, "[line 11] Expected 3 but got 2."},
+		{"testMessage", TEST_FAILED, `[line 15] Strings differ. Expected "ab" but got "ac".`},
+		{"testError", TEST_ERROR, "[line 19] Only instances have properties."},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("got %d results, expected %d", len(results), len(expected))
+	}
+
+	for index, result := range results {
+		if result.Name != expected[index].name || result.Status != expected[index].status || result.Message != expected[index].message {
+			t.Errorf("got %s %s %q, expected %s %s %q", result.Name, result.Status, result.Message, expected[index].name, expected[index].status, expected[index].message)
+		}
+	}
+}
+
+func TestFailedTestShowsOutput(t *testing.T) {
+	engine := NewLoxWithOutput(io.Discard, io.Discard)
+	results := engine.RunTestFile(filepath.Join("testdata", "runner", "assertions_test.lox"))
+
+	if report := results[1].String(); !strings.Contains(report, "    output:\n        adding\n") {
+		t.Errorf("report doesn't show the output of the test:\n%s", report)
+	}
+}
@@ -0,0 +1,257 @@
+package lsp
+
+import (
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser"
+	"github.com/lukas-reining/lox/scanner"
+	"strings"
+	"unicode/utf16"
+)
+
+type document struct {
+	uri         string
+	text        string
+	lines       []string
+	tokens      []scanner.Token
+	symbols     *interpeter.SymbolTable
+	diagnostics []Diagnostic
+}
+
+func analyze(uri string, text string) *document {
+	doc := &document{uri: uri, text: text, lines: strings.Split(text, "\n"), symbols: interpeter.NewSymbolTable(), diagnostics: []Diagnostic{}}
+
+	sourceScanner := scanner.NewScanner(text)
+	tokens, err := sourceScanner.ScanTokens()
+	if err != nil {
+		doc.addDiagnostic(err.Line(), err.Message())
+		return doc
+	}
+	doc.tokens = tokens
+
+	sourceParser := parser.NewParser[any, interpeter.RuntimeError](tokens)
+	statements, parseErr := sourceParser.Parse()
+	if parseErr != nil {
+		if len(parseErr.Lexeme()) == 0 {
+			doc.addDiagnostic(parseErr.Line(), parseErr.Message())
+		} else {
+			doc.addDiagnostic(parseErr.Line(), "at '"+parseErr.Lexeme()+"': "+parseErr.Message())
+		}
+		return doc
+	}
+
+	interpreter := interpeter.NewInterpreter()
+	resolver := interpeter.NewResolverWithSymbols(&interpreter, doc.symbols)
+	if err := resolver.Resolve(statements); err != nil {
+		doc.addDiagnostic(err.Line(), err.Message())
+	}
+
+	for _, warning := range resolver.Warnings() {
+		doc.addDiagnosticWithSeverity(warning.Line(), warning.Message(), SEVERITY_WARNING)
+	}
+
+	return doc
+}
+
+func (d *document) addDiagnostic(line int, message string) {
+	d.addDiagnosticWithSeverity(line, message, SEVERITY_ERROR)
+}
+
+func (d *document) addDiagnosticWithSeverity(line int, message string, severity int) {
+	d.diagnostics = append(d.diagnostics, Diagnostic{
+		Range:    d.lineRange(line),
+		Severity: severity,
+		Source:   "lox",
+		Message:  message,
+	})
+}
+
+// LSP positions count UTF-16 code units, while the scanner counts characters.
+func utf16Length(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+func (d *document) line(line int) string {
+	if line < 1 || line > len(d.lines) {
+		return ""
+	}
+	return strings.TrimRight(d.lines[line-1], "\r")
+}
+
+// Converts a column of the scanner, counting characters from 1, to an LSP character offset.
+func (d *document) character(line int, column int) int {
+	text := d.line(line)
+	characters := 0
+	for offset := range text {
+		if characters == column-1 {
+			return utf16Length(text[:offset])
+		}
+		characters++
+	}
+	return utf16Length(text)
+}
+
+func (d *document) lineRange(line int) Range {
+	return Range{
+		Start: Position{Line: line - 1, Character: 0},
+		End:   Position{Line: line - 1, Character: utf16Length(d.line(line))},
+	}
+}
+
+func (d *document) tokenRange(token scanner.Token) Range {
+	start := Position{Line: token.Line - 1, Character: d.character(token.Line, token.Column)}
+	end := Position{Line: token.Line - 1, Character: start.Character + utf16Length(token.Lexeme)}
+	return Range{Start: start, End: end}
+}
+
+func (d *document) tokenAt(position Position) (scanner.Token, bool) {
+	for _, token := range d.tokens {
+		if token.Type != scanner.IDENTIFIER {
+			continue
+		}
+
+		tokenRange := d.tokenRange(token)
+		if tokenRange.Start.Line == position.Line &&
+			tokenRange.Start.Character <= position.Character &&
+			position.Character <= tokenRange.End.Character {
+			return token, true
+		}
+	}
+
+	return scanner.Token{}, false
+}
+
+func (d *document) symbolAt(position Position) *interpeter.Symbol {
+	token, ok := d.tokenAt(position)
+	if !ok {
+		return nil
+	}
+
+	return d.symbols.Lookup(token)
+}
+
+func (d *document) location(token scanner.Token) Location {
+	return Location{Uri: d.uri, Range: d.tokenRange(token)}
+}
+
+func (d *document) definition(position Position) []Location {
+	symbol := d.symbolAt(position)
+	if symbol == nil {
+		return []Location{}
+	}
+
+	return []Location{d.location(symbol.Name)}
+}
+
+func (d *document) references(position Position, includeDeclaration bool) []Location {
+	locations := []Location{}
+
+	symbol := d.symbolAt(position)
+	if symbol == nil {
+		return locations
+	}
+
+	if includeDeclaration {
+		locations = append(locations, d.location(symbol.Name))
+	}
+
+	for _, reference := range symbol.References {
+		locations = append(locations, d.location(reference))
+	}
+
+	return locations
+}
+
+func (d *document) hover(position Position) *Hover {
+	token, ok := d.tokenAt(position)
+	if !ok {
+		return nil
+	}
+
+	symbol := d.symbols.Lookup(token)
+	if symbol == nil {
+		return nil
+	}
+
+	kind := string(symbol.Kind)
+	if symbol.Global {
+		kind = "global " + kind
+	}
+
+	return &Hover{
+		Contents: markupContent{Kind: "markdown", Value: "(" + kind + ") `" + symbol.Name.Lexeme + "`"},
+		Range:    d.tokenRange(token),
+	}
+}
+
+func documentSymbolKind(kind interpeter.SymbolKind) int {
+	switch kind {
+	case interpeter.CLASS_SYMBOL:
+		return SYMBOL_KIND_CLASS
+	case interpeter.TRAIT_SYMBOL:
+		return SYMBOL_KIND_INTERFACE
+	case interpeter.METHOD_SYMBOL:
+		return SYMBOL_KIND_METHOD
+	default:
+		return SYMBOL_KIND_FUNCTION
+	}
+}
+
+func (d *document) documentSymbol(symbol *interpeter.Symbol) DocumentSymbol {
+	result := DocumentSymbol{
+		Name:           symbol.Name.Lexeme,
+		Detail:         string(symbol.Kind),
+		Kind:           documentSymbolKind(symbol.Kind),
+		Range:          d.tokenRange(symbol.Name),
+		SelectionRange: d.tokenRange(symbol.Name),
+	}
+
+	for _, member := range symbol.Members {
+		result.Children = append(result.Children, d.documentSymbol(member))
+	}
+
+	return result
+}
+
+func (d *document) documentSymbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, symbol := range d.symbols.Symbols {
+		if symbol.Kind == interpeter.FUNCTION_SYMBOL || symbol.Kind == interpeter.CLASS_SYMBOL || symbol.Kind == interpeter.TRAIT_SYMBOL {
+			symbols = append(symbols, d.documentSymbol(symbol))
+		}
+	}
+	return symbols
+}
+
+func (d *document) completions() []CompletionItem {
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+
+	for _, keyword := range scanner.Keywords() {
+		items = append(items, CompletionItem{Label: keyword, Kind: COMPLETION_KIND_KEYWORD})
+		seen[keyword] = true
+	}
+
+	for _, name := range interpeter.GetGlobalEnv().Names() {
+		items = append(items, CompletionItem{Label: name, Kind: COMPLETION_KIND_FUNCTION, Detail: "builtin"})
+		seen[name] = true
+	}
+
+	for _, symbol := range d.symbols.Globals() {
+		if seen[symbol.Name.Lexeme] {
+			continue
+		}
+		seen[symbol.Name.Lexeme] = true
+
+		kind := COMPLETION_KIND_VARIABLE
+		switch symbol.Kind {
+		case interpeter.FUNCTION_SYMBOL:
+			kind = COMPLETION_KIND_FUNCTION
+		case interpeter.CLASS_SYMBOL, interpeter.TRAIT_SYMBOL:
+			kind = COMPLETION_KIND_CLASS
+		}
+
+		items = append(items, CompletionItem{Label: symbol.Name.Lexeme, Kind: kind, Detail: string(symbol.Kind)})
+	}
+
+	return items
+}
@@ -0,0 +1,213 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+type Server struct {
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: map[string]*document{},
+	}
+}
+
+func (s *Server) Serve() int {
+	for {
+		msg, err := s.read()
+		if err != nil {
+			if err == io.EOF && s.shutdown {
+				return 0
+			}
+			return 1
+		}
+
+		if msg == nil {
+			s.respondError(nil, PARSE_ERROR, "Could not parse message.")
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+
+		s.handle(msg)
+	}
+}
+
+func (s *Server) read() (*message, error) {
+	headers, err := textproto.NewReader(s.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, nil
+	}
+
+	return &msg, nil
+}
+
+func (s *Server) write(value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *Server) respond(id *json.RawMessage, result any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func (s *Server) respondError(id *json.RawMessage, code int, message string) {
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "error": responseError{Code: code, Message: message}})
+}
+
+func (s *Server) notify(method string, params any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *Server) publishDiagnostics(doc *document) {
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{Uri: doc.uri, Diagnostics: doc.diagnostics})
+}
+
+func (s *Server) handle(msg *message) {
+	var result any
+	var err error
+
+	switch msg.Method {
+	case "initialize":
+		result = map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"change":    1,
+					"save":      map[string]any{"includeText": true},
+				},
+				"definitionProvider":     true,
+				"referencesProvider":     true,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"completionProvider":     map[string]any{},
+			},
+			"serverInfo": map[string]any{"name": "glox"},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			doc := analyze(params.TextDocument.Uri, params.TextDocument.Text)
+			s.documents[doc.uri] = doc
+			s.publishDiagnostics(doc)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			text := params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.documents[params.TextDocument.Uri] = analyze(params.TextDocument.Uri, text)
+		}
+	case "textDocument/didSave":
+		var params didSaveParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			doc := s.documents[params.TextDocument.Uri]
+			if params.Text != nil {
+				doc = analyze(params.TextDocument.Uri, *params.Text)
+				s.documents[doc.uri] = doc
+			}
+			if doc != nil {
+				s.publishDiagnostics(doc)
+			}
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			delete(s.documents, params.TextDocument.Uri)
+			s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{Uri: params.TextDocument.Uri, Diagnostics: []Diagnostic{}})
+		}
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = []Location{}
+			if doc, ok := s.documents[params.TextDocument.Uri]; ok {
+				result = doc.definition(params.Position)
+			}
+		}
+	case "textDocument/references":
+		var params referenceParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = []Location{}
+			if doc, ok := s.documents[params.TextDocument.Uri]; ok {
+				result = doc.references(params.Position, params.Context.IncludeDeclaration)
+			}
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			if doc, ok := s.documents[params.TextDocument.Uri]; ok {
+				if hover := doc.hover(params.Position); hover != nil {
+					result = hover
+				}
+			}
+		}
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = []DocumentSymbol{}
+			if doc, ok := s.documents[params.TextDocument.Uri]; ok {
+				result = doc.documentSymbols()
+			}
+		}
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = []CompletionItem{}
+			if doc, ok := s.documents[params.TextDocument.Uri]; ok {
+				result = doc.completions()
+			}
+		}
+	default:
+		if msg.Id != nil {
+			s.respondError(msg.Id, METHOD_NOT_FOUND, "Method not found: "+msg.Method)
+		}
+		return
+	}
+
+	if msg.Id == nil {
+		return
+	}
+
+	if err != nil {
+		s.respondError(msg.Id, INVALID_PARAMS, err.Error())
+	} else {
+		s.respond(msg.Id, result)
+	}
+}
@@ -4,26 +4,50 @@ import (
 	"fmt"
 	"github.com/lukas-reining/lox/interpeter"
 	"github.com/lukas-reining/lox/lox"
+	"github.com/lukas-reining/lox/lsp"
 	"github.com/lukas-reining/lox/parser"
 	"os"
 )
 
+func exitCode(err error) int {
+	switch err.(type) {
+	case nil:
+		return 0
+	case parser.ParseError:
+		return 65
+	case interpeter.RuntimeError:
+		return 70
+	}
+	return 1
+}
+
 func main() {
 	args := os.Args[1:]
 	loxEngine := lox.NewLox()
 
+	if len(args) > 0 {
+		switch args[0] {
+		case "dump":
+			os.Exit(dump(loxEngine, args[1:]))
+		case "debug":
+			os.Exit(debug(loxEngine, args[1:]))
+		case "run":
+			os.Exit(run(loxEngine, args[1:]))
+		case "test":
+			os.Exit(test(loxEngine, args[1:]))
+		case "cover":
+			os.Exit(cover(args[1:]))
+		case "lsp":
+			os.Exit(lsp.NewServer(os.Stdin, os.Stdout).Serve())
+		}
+	}
+
 	if len(args) > 1 {
 		fmt.Println("Usage: glox [script]")
 		os.Exit(64)
 	} else if len(args) == 1 {
 		err := loxEngine.RunFile(args[0])
-
-		switch err.(type) {
-		case parser.ParseError:
-			os.Exit(65)
-		case interpeter.RuntimeError:
-			os.Exit(70)
-		}
+		os.Exit(exitCode(err))
 	} else {
 		loxEngine.RunPrompt()
 	}
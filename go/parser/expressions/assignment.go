@@ -7,14 +7,16 @@ import (
 type Assignment[T any, Err error] struct {
 	Expression[T, Err]
 
-	Name  scanner.Token
-	Value Expression[T, Err]
+	Name     scanner.Token
+	Operator scanner.Token
+	Value    Expression[T, Err]
 }
 
-func NewAssignment[T any, Err error](name scanner.Token, value Expression[T, Err]) *Assignment[T, Err] {
+func NewAssignment[T any, Err error](name scanner.Token, operator scanner.Token, value Expression[T, Err]) *Assignment[T, Err] {
 	return &Assignment[T, Err]{
-		Name:  name,
-		Value: value,
+		Name:     name,
+		Operator: operator,
+		Value:    value,
 	}
 }
 
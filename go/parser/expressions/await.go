@@ -0,0 +1,23 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Await[T any, Err error] struct {
+	Expression[T, Err]
+
+	Keyword scanner.Token
+	Value   Expression[T, Err]
+}
+
+func NewAwait[T any, Err error](keyword scanner.Token, value Expression[T, Err]) *Await[T, Err] {
+	return &Await[T, Err]{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
+
+func (e *Await[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitAwaitExpression(e)
+}
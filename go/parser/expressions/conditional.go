@@ -0,0 +1,27 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Conditional[T any, Err error] struct {
+	Expression[T, Err]
+
+	Condition  Expression[T, Err]
+	Question   scanner.Token
+	ThenBranch Expression[T, Err]
+	ElseBranch Expression[T, Err]
+}
+
+func NewConditional[T any, Err error](condition Expression[T, Err], question scanner.Token, thenBranch Expression[T, Err], elseBranch Expression[T, Err]) *Conditional[T, Err] {
+	return &Conditional[T, Err]{
+		Condition:  condition,
+		Question:   question,
+		ThenBranch: thenBranch,
+		ElseBranch: elseBranch,
+	}
+}
+
+func (e *Conditional[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitConditionalExpression(e)
+}
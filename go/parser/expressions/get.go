@@ -7,8 +7,9 @@ import (
 type Get[T any, Err error] struct {
 	Expression[T, Err]
 
-	Object Expression[T, Err]
-	Name   scanner.Token
+	Object   Expression[T, Err]
+	Name     scanner.Token
+	Optional bool
 }
 
 func NewGet[T any, Err error](value Expression[T, Err], name scanner.Token) *Get[T, Err] {
@@ -18,6 +19,14 @@ func NewGet[T any, Err error](value Expression[T, Err], name scanner.Token) *Get
 	}
 }
 
+func NewOptionalGet[T any, Err error](value Expression[T, Err], name scanner.Token) *Get[T, Err] {
+	return &Get[T, Err]{
+		Object:   value,
+		Name:     name,
+		Optional: true,
+	}
+}
+
 func (e *Get[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitGetExpression(e)
 }
@@ -1,13 +1,19 @@
 package expressions
 
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
 type Grouping[T any, Err error] struct {
 	Expression[T, Err]
-	Exp Expression[T, Err]
+	Paren scanner.Token
+	Exp   Expression[T, Err]
 }
 
-func NewGrouping[T any, Err error](expression Expression[T, Err]) *Grouping[T, Err] {
+func NewGrouping[T any, Err error](paren scanner.Token, expression Expression[T, Err]) *Grouping[T, Err] {
 	return &Grouping[T, Err]{
-		Exp: expression,
+		Paren: paren,
+		Exp:   expression,
 	}
 }
 
@@ -0,0 +1,25 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Index[T any, Err error] struct {
+	Expression[T, Err]
+
+	Object  Expression[T, Err]
+	Bracket scanner.Token
+	Index   Expression[T, Err]
+}
+
+func NewIndex[T any, Err error](object Expression[T, Err], bracket scanner.Token, index Expression[T, Err]) *Index[T, Err] {
+	return &Index[T, Err]{
+		Object:  object,
+		Bracket: bracket,
+		Index:   index,
+	}
+}
+
+func (e *Index[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitIndexExpression(e)
+}
@@ -0,0 +1,24 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Lambda[T any, Err error] struct {
+	Expression[T, Err]
+
+	Keyword scanner.Token
+	// Holds a *statements.Function[T, Err], the statements package depends on this one.
+	Function any
+}
+
+func NewLambda[T any, Err error](keyword scanner.Token, function any) *Lambda[T, Err] {
+	return &Lambda[T, Err]{
+		Keyword:  keyword,
+		Function: function,
+	}
+}
+
+func (e *Lambda[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitLambdaExpression(e)
+}
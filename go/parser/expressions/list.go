@@ -0,0 +1,23 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type List[T any, Err error] struct {
+	Expression[T, Err]
+
+	Bracket  scanner.Token
+	Elements []Expression[T, Err]
+}
+
+func NewList[T any, Err error](bracket scanner.Token, elements []Expression[T, Err]) *List[T, Err] {
+	return &List[T, Err]{
+		Bracket:  bracket,
+		Elements: elements,
+	}
+}
+
+func (e *List[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitListExpression(e)
+}
@@ -1,13 +1,20 @@
 package expressions
 
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
 type Literal[T any, Err error] struct {
 	Expression[T, Err]
 
+	// The token the literal was written as, or the one of the expression it was folded from.
+	Token   scanner.Token
 	Literal any
 }
 
-func NewLiteral[T any, Err error](literal any) *Literal[T, Err] {
+func NewLiteral[T any, Err error](token scanner.Token, literal any) *Literal[T, Err] {
 	return &Literal[T, Err]{
+		Token:   token,
 		Literal: literal,
 	}
 }
@@ -0,0 +1,25 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type MultiAssignment[T any, Err error] struct {
+	Expression[T, Err]
+
+	Targets []Expression[T, Err]
+	Equals  scanner.Token
+	Values  []Expression[T, Err]
+}
+
+func NewMultiAssignment[T any, Err error](targets []Expression[T, Err], equals scanner.Token, values []Expression[T, Err]) *MultiAssignment[T, Err] {
+	return &MultiAssignment[T, Err]{
+		Targets: targets,
+		Equals:  equals,
+		Values:  values,
+	}
+}
+
+func (e *MultiAssignment[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitMultiAssignmentExpression(e)
+}
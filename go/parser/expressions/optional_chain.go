@@ -0,0 +1,19 @@
+package expressions
+
+// Wraps a chain of property accesses and calls containing at least one "?.",
+// so that the whole chain evaluates to nil once an optional access hits nil.
+type OptionalChain[T any, Err error] struct {
+	Expression[T, Err]
+
+	Chain Expression[T, Err]
+}
+
+func NewOptionalChain[T any, Err error](chain Expression[T, Err]) *OptionalChain[T, Err] {
+	return &OptionalChain[T, Err]{
+		Chain: chain,
+	}
+}
+
+func (e *OptionalChain[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitOptionalChainExpression(e)
+}
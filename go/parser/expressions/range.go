@@ -0,0 +1,25 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Range[T any, Err error] struct {
+	Expression[T, Err]
+
+	Start    Expression[T, Err]
+	Operator scanner.Token
+	End      Expression[T, Err]
+}
+
+func NewRange[T any, Err error](start Expression[T, Err], operator scanner.Token, end Expression[T, Err]) *Range[T, Err] {
+	return &Range[T, Err]{
+		Start:    start,
+		Operator: operator,
+		End:      end,
+	}
+}
+
+func (e *Range[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitRangeExpression(e)
+}
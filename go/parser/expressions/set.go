@@ -7,16 +7,18 @@ import (
 type Set[T any, Err error] struct {
 	Expression[T, Err]
 
-	Object Expression[T, Err]
-	Name   scanner.Token
-	Value  Expression[T, Err]
+	Object   Expression[T, Err]
+	Name     scanner.Token
+	Operator scanner.Token
+	Value    Expression[T, Err]
 }
 
-func NewSet[T any, Err error](object Expression[T, Err], name scanner.Token, value Expression[T, Err]) *Set[T, Err] {
+func NewSet[T any, Err error](object Expression[T, Err], name scanner.Token, operator scanner.Token, value Expression[T, Err]) *Set[T, Err] {
 	return &Set[T, Err]{
-		Object: object,
-		Name:   name,
-		Value:  value,
+		Object:   object,
+		Name:     name,
+		Operator: operator,
+		Value:    value,
 	}
 }
 
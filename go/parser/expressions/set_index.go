@@ -0,0 +1,29 @@
+package expressions
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type SetIndex[T any, Err error] struct {
+	Expression[T, Err]
+
+	Object   Expression[T, Err]
+	Bracket  scanner.Token
+	Index    Expression[T, Err]
+	Operator scanner.Token
+	Value    Expression[T, Err]
+}
+
+func NewSetIndex[T any, Err error](object Expression[T, Err], bracket scanner.Token, index Expression[T, Err], operator scanner.Token, value Expression[T, Err]) *SetIndex[T, Err] {
+	return &SetIndex[T, Err]{
+		Object:   object,
+		Bracket:  bracket,
+		Index:    index,
+		Operator: operator,
+		Value:    value,
+	}
+}
+
+func (e *SetIndex[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitSetIndexExpression(e)
+}
@@ -12,4 +12,13 @@ type Visitor[T any, Err error] interface {
 	VisitGetExpression(exp *Get[T, Err]) (T, Err)
 	VisitSetExpression(exp *Set[T, Err]) (T, Err)
 	VisitThisExpression(exp *This[T, Err]) (T, Err)
+	VisitListExpression(exp *List[T, Err]) (T, Err)
+	VisitIndexExpression(exp *Index[T, Err]) (T, Err)
+	VisitSetIndexExpression(exp *SetIndex[T, Err]) (T, Err)
+	VisitConditionalExpression(exp *Conditional[T, Err]) (T, Err)
+	VisitOptionalChainExpression(exp *OptionalChain[T, Err]) (T, Err)
+	VisitLambdaExpression(exp *Lambda[T, Err]) (T, Err)
+	VisitMultiAssignmentExpression(exp *MultiAssignment[T, Err]) (T, Err)
+	VisitRangeExpression(exp *Range[T, Err]) (T, Err)
+	VisitAwaitExpression(exp *Await[T, Err]) (T, Err)
 }
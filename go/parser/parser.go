@@ -11,6 +11,9 @@ import (
 type Parser[T any, Err error] struct {
 	Tokens  []scanner.Token
 	current int
+
+	// Whether the function body being parsed contains a yield.
+	yielded bool
 }
 
 func NewParser[T any, Err error](tokens []scanner.Token) *Parser[T, Err] {
@@ -36,6 +39,13 @@ func (p *Parser[T, Err]) peek() scanner.Token {
 	return p.Tokens[p.current]
 }
 
+func (p *Parser[T, Err]) peekAt(offset int) scanner.Token {
+	if p.current+offset >= len(p.Tokens) {
+		return p.Tokens[len(p.Tokens)-1]
+	}
+	return p.Tokens[p.current+offset]
+}
+
 func (p *Parser[T, Err]) check(tokenType scanner.TokenType) bool {
 	if p.isAtEnd() {
 		return false
@@ -88,26 +98,49 @@ func (p *Parser[T, Err]) synchronize() {
 
 func (p *Parser[T, Err]) primary() (expressions.Expression[T, Err], ParseError) {
 	if p.match(scanner.FALSE) {
-		return expressions.NewLiteral[T, Err](false), nil
+		return expressions.NewLiteral[T, Err](p.previous(), false), nil
 	}
 
 	if p.match(scanner.TRUE) {
-		return expressions.NewLiteral[T, Err](true), nil
+		return expressions.NewLiteral[T, Err](p.previous(), true), nil
 	}
 
 	if p.match(scanner.NIL) {
-		return expressions.NewLiteral[T, Err](nil), nil
+		return expressions.NewLiteral[T, Err](p.previous(), nil), nil
 	}
 
 	if p.match(scanner.NUMBER, scanner.STRING) {
-		return expressions.NewLiteral[T, Err](p.previous().Literal), nil
+		return expressions.NewLiteral[T, Err](p.previous(), p.previous().Literal), nil
+	}
+
+	if p.match(scanner.INTERPOLATION) {
+		return p.interpolation()
+	}
+
+	if p.match(scanner.LEFT_BRACKET) {
+		return p.list()
+	}
+
+	if p.match(scanner.FUN) {
+		return p.lambda()
+	}
+
+	if p.check(scanner.ASYNC) && p.peekAt(1).Type == scanner.FUN {
+		p.advance()
+		p.advance()
+		return p.asyncLambda()
+	}
+
+	if p.isArrowFunction() {
+		return p.arrowFunction()
 	}
 
 	if p.match(scanner.LEFT_PAREN) {
+		paren := p.previous()
 		if expr, err := p.expression(); err != nil {
 			return nil, err
 		} else if _, err := p.consume(scanner.RIGHT_PAREN, "Expect ')' after expression."); err == nil {
-			return expressions.NewGrouping[T, Err](expr), nil
+			return expressions.NewGrouping[T, Err](paren, expr), nil
 		} else {
 			return nil, err
 		}
@@ -133,12 +166,64 @@ func (p *Parser[T, Err]) primary() (expressions.Expression[T, Err], ParseError)
 		return expressions.NewVariable[T, Err](p.previous()), nil
 	}
 
-	return nil, NewParseError(p.current, p.peek().Lexeme, "Expected expression!")
+	return nil, NewParseError(p.peek().Line, p.peek().Lexeme, "Expected expression!")
+}
+
+func (p *Parser[T, Err]) list() (expressions.Expression[T, Err], ParseError) {
+	bracket := p.previous()
+	var elements []expressions.Expression[T, Err]
+
+	if !p.check(scanner.RIGHT_BRACKET) {
+		for {
+			element, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+
+			if !p.match(scanner.COMMA) || p.check(scanner.RIGHT_BRACKET) {
+				break
+			}
+		}
+	}
+
+	if _, err := p.consume(scanner.RIGHT_BRACKET, "Expect ']' after list elements."); err != nil {
+		return nil, err
+	}
+
+	return expressions.NewList(bracket, elements), nil
+}
+
+// Lowers "a${b}c" into the concatenation ("a" + b) + "c" using INTERPOLATION operators,
+// which stringify both operands.
+func (p *Parser[T, Err]) interpolation() (expressions.Expression[T, Err], ParseError) {
+	segment := p.previous()
+	operator := scanner.NewToken(scanner.INTERPOLATION, "+", nil, segment.Line, segment.Column)
+
+	var expr expressions.Expression[T, Err] = expressions.NewLiteral[T, Err](segment, segment.Literal)
+	for {
+		embedded, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		expr = expressions.NewBinary[T, Err](expr, operator, embedded)
+
+		if p.match(scanner.INTERPOLATION) {
+			expr = expressions.NewBinary[T, Err](expr, operator, expressions.NewLiteral[T, Err](p.previous(), p.previous().Literal))
+			continue
+		}
+
+		end, err := p.consume(scanner.STRING, "Expect '}' after interpolated expression.")
+		if err != nil {
+			return nil, err
+		}
+		return expressions.NewBinary[T, Err](expr, operator, expressions.NewLiteral[T, Err](end, end.Literal)), nil
+	}
 }
 
 func (p *Parser[T, Err]) unary() (expressions.Expression[T, Err], ParseError) {
 
-	if p.match(scanner.BANG, scanner.MINUS) {
+	if p.match(scanner.BANG, scanner.MINUS, scanner.TILDE) {
 		operator := p.previous()
 
 		if right, err := p.unary(); err != nil {
@@ -148,7 +233,37 @@ func (p *Parser[T, Err]) unary() (expressions.Expression[T, Err], ParseError) {
 		}
 	}
 
-	return p.call()
+	if p.match(scanner.AWAIT) {
+		keyword := p.previous()
+
+		if value, err := p.unary(); err != nil {
+			return nil, err
+		} else {
+			return expressions.NewAwait(keyword, value), nil
+		}
+	}
+
+	return p.power()
+}
+
+// Exponentiation is right-associative and binds tighter than a unary operator on its left.
+func (p *Parser[T, Err]) power() (expressions.Expression[T, Err], ParseError) {
+	left, err := p.call()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(scanner.STAR_STAR) {
+		operator := p.previous()
+		if right, err := p.unary(); err != nil {
+			return nil, err
+		} else {
+			return expressions.NewBinary(left, operator, right), nil
+		}
+	}
+
+	return left, nil
 }
 
 func (p *Parser[T, Err]) factor() (expressions.Expression[T, Err], ParseError) {
@@ -158,7 +273,7 @@ func (p *Parser[T, Err]) factor() (expressions.Expression[T, Err], ParseError) {
 		return nil, err
 	}
 
-	for p.match(scanner.SLASH, scanner.STAR) {
+	for p.match(scanner.SLASH, scanner.STAR, scanner.PERCENT) {
 		operator := p.previous()
 		if right, err := p.unary(); err != nil {
 			return nil, err
@@ -191,14 +306,14 @@ func (p *Parser[T, Err]) term() (expressions.Expression[T, Err], ParseError) {
 	return left, nil
 }
 
-func (p *Parser[T, Err]) comparison() (expressions.Expression[T, Err], ParseError) {
+func (p *Parser[T, Err]) shift() (expressions.Expression[T, Err], ParseError) {
 	left, err := p.term()
 
 	if err != nil {
 		return nil, err
 	}
 
-	for p.match(scanner.GREATER, scanner.GREATER_EQUAL, scanner.LESS, scanner.LESS_EQUAL) {
+	for p.match(scanner.LESS_LESS, scanner.GREATER_GREATER) {
 		operator := p.previous()
 
 		if right, err := p.term(); err != nil {
@@ -211,6 +326,101 @@ func (p *Parser[T, Err]) comparison() (expressions.Expression[T, Err], ParseErro
 	return left, nil
 }
 
+func (p *Parser[T, Err]) bitwiseAnd() (expressions.Expression[T, Err], ParseError) {
+	left, err := p.shift()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(scanner.AMPERSAND) {
+		operator := p.previous()
+
+		if right, err := p.shift(); err != nil {
+			return nil, err
+		} else {
+			left = expressions.NewBinary(left, operator, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser[T, Err]) bitwiseXor() (expressions.Expression[T, Err], ParseError) {
+	left, err := p.bitwiseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(scanner.CARET) {
+		operator := p.previous()
+
+		if right, err := p.bitwiseAnd(); err != nil {
+			return nil, err
+		} else {
+			left = expressions.NewBinary(left, operator, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser[T, Err]) bitwiseOr() (expressions.Expression[T, Err], ParseError) {
+	left, err := p.bitwiseXor()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(scanner.PIPE) {
+		operator := p.previous()
+
+		if right, err := p.bitwiseXor(); err != nil {
+			return nil, err
+		} else {
+			left = expressions.NewBinary(left, operator, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser[T, Err]) comparison() (expressions.Expression[T, Err], ParseError) {
+	left, err := p.rangeExpression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(scanner.GREATER, scanner.GREATER_EQUAL, scanner.LESS, scanner.LESS_EQUAL) {
+		operator := p.previous()
+
+		if right, err := p.rangeExpression(); err != nil {
+			return nil, err
+		} else {
+			left = expressions.NewBinary(left, operator, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser[T, Err]) rangeExpression() (expressions.Expression[T, Err], ParseError) {
+	start, err := p.bitwiseOr()
+	if err != nil || !p.match(scanner.DOT_DOT) {
+		return start, err
+	}
+
+	operator := p.previous()
+	end, err := p.bitwiseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	return expressions.NewRange(start, operator, end), nil
+}
+
 func (p *Parser[T, Err]) equality() (expressions.Expression[T, Err], ParseError) {
 	left, err := p.comparison()
 
@@ -275,14 +485,63 @@ func (p *Parser[T, Err]) or() (expressions.Expression[T, Err], ParseError) {
 	return expr, nil
 }
 
+func (p *Parser[T, Err]) coalesce() (expressions.Expression[T, Err], ParseError) {
+	left, err := p.or()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(scanner.QUESTION_QUESTION) {
+		operator := p.previous()
+
+		if right, err := p.or(); err != nil {
+			return nil, err
+		} else {
+			left = expressions.NewLogical(left, operator, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser[T, Err]) conditional() (expressions.Expression[T, Err], ParseError) {
+	condition, err := p.coalesce()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(scanner.QUESTION) {
+		return condition, nil
+	}
+
+	question := p.previous()
+	thenBranch, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.COLON, "Expect ':' after then branch of conditional expression."); err != nil {
+		return nil, err
+	}
+
+	elseBranch, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+
+	return expressions.NewConditional(condition, question, thenBranch, elseBranch), nil
+}
+
 func (p *Parser[T, Err]) assignment() (expressions.Expression[T, Err], ParseError) {
-	exp, err := p.or()
+	exp, err := p.conditional()
 
 	if err != nil {
 		return nil, err
 	}
 
-	if p.match(scanner.EQUAL) {
+	if p.match(scanner.EQUAL, scanner.PLUS_EQUAL, scanner.MINUS_EQUAL, scanner.STAR_EQUAL, scanner.SLASH_EQUAL) {
 		token := p.previous()
 		value, err := p.assignment()
 
@@ -293,9 +552,11 @@ func (p *Parser[T, Err]) assignment() (expressions.Expression[T, Err], ParseErro
 		switch expression := exp.(type) {
 		case *expressions.Variable[T, Err]:
 			name := expression.Name
-			return expressions.NewAssignment(name, value), nil
+			return expressions.NewAssignment(name, token, value), nil
 		case *expressions.Get[T, Err]:
-			return expressions.NewSet(expression.Object, expression.Name, value), nil
+			return expressions.NewSet(expression.Object, expression.Name, token, value), nil
+		case *expressions.Index[T, Err]:
+			return expressions.NewSetIndex(expression.Object, expression.Bracket, expression.Index, token, value), nil
 		}
 
 		return nil, NewParseError(token.Line, token.Lexeme, "Invalid assignment target.")
@@ -309,20 +570,74 @@ func (p *Parser[T, Err]) expression() (expressions.Expression[T, Err], ParseErro
 }
 
 func (p *Parser[T, Err]) expressionStatement() (statements.Statement[T, Err], ParseError) {
+	line := p.peek().Line
 	value, err := p.expression()
 
 	if err != nil {
 		return nil, err
 	}
 
+	if p.check(scanner.COMMA) {
+		if value, err = p.multiAssignment(value); err != nil {
+			return nil, err
+		}
+	}
+
 	if _, err := p.consume(scanner.SEMICOLON, "Expect ';' after value."); err == nil {
-		return statements.NewExpression(value), nil
+		return statements.NewExpression(line, value), nil
 	} else {
 		return nil, err
 	}
 }
 
+func (p *Parser[T, Err]) multiAssignment(first expressions.Expression[T, Err]) (expressions.Expression[T, Err], ParseError) {
+	targets := []expressions.Expression[T, Err]{first}
+	for p.match(scanner.COMMA) {
+		target, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	equals, err := p.consume(scanner.EQUAL, "Expect '=' after assignment targets.")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		switch target.(type) {
+		case *expressions.Variable[T, Err], *expressions.Get[T, Err], *expressions.Index[T, Err]:
+		default:
+			return nil, NewParseError(equals.Line, equals.Lexeme, "Invalid assignment target.")
+		}
+	}
+
+	values, err := p.expressionList()
+	if err != nil {
+		return nil, err
+	}
+
+	return expressions.NewMultiAssignment(targets, equals, values), nil
+}
+
+func (p *Parser[T, Err]) expressionList() ([]expressions.Expression[T, Err], ParseError) {
+	var values []expressions.Expression[T, Err]
+	for {
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if !p.match(scanner.COMMA) {
+			return values, nil
+		}
+	}
+}
+
 func (p *Parser[T, Err]) printStatement() (statements.Statement[T, Err], ParseError) {
+	keyword := p.previous()
 	value, err := p.expression()
 
 	if err != nil {
@@ -330,7 +645,7 @@ func (p *Parser[T, Err]) printStatement() (statements.Statement[T, Err], ParseEr
 	}
 
 	if _, err := p.consume(scanner.SEMICOLON, "Expect ';' after value."); err == nil {
-		return statements.NewPrintStatement(value), nil
+		return statements.NewPrintStatement(keyword.Line, value), nil
 	} else {
 		return nil, err
 	}
@@ -355,15 +670,17 @@ func (p *Parser[T, Err]) block() ([]statements.Statement[T, Err], ParseError) {
 }
 
 func (p *Parser[T, Err]) blockStatement() (statements.Statement[T, Err], ParseError) {
+	brace := p.previous()
 	if block, err := p.block(); err != nil {
 		return nil, err
 	} else {
-		return statements.NewBlock(block), nil
+		return statements.NewBlock(brace.Line, block), nil
 	}
 
 }
 
 func (p *Parser[T, Err]) ifStatement() (statements.Statement[T, Err], ParseError) {
+	keyword := p.previous()
 	if _, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after 'if'."); err != nil {
 		return nil, err
 	}
@@ -393,10 +710,11 @@ func (p *Parser[T, Err]) ifStatement() (statements.Statement[T, Err], ParseError
 		elseBranch = statement
 	}
 
-	return statements.NewIf(condition, ifBranch, elseBranch), nil
+	return statements.NewIf(keyword.Line, condition, ifBranch, elseBranch), nil
 }
 
 func (p *Parser[T, Err]) whileStatement() (statements.Statement[T, Err], ParseError) {
+	keyword := p.previous()
 
 	if _, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after 'while'."); err != nil {
 		return nil, err
@@ -416,71 +734,285 @@ func (p *Parser[T, Err]) whileStatement() (statements.Statement[T, Err], ParseEr
 		return nil, err
 	}
 
-	return statements.NewWhile(condition, body), nil
+	return statements.NewWhile(keyword.Line, condition, body), nil
 }
 
-func (p *Parser[T, Err]) forStatement() (statements.Statement[T, Err], ParseError) {
-	if _, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after 'for'."); err != nil {
-		return nil, err
-	}
+func (p *Parser[T, Err]) matchStatement() (statements.Statement[T, Err], ParseError) {
+	keyword := p.previous()
 
-	var initializer statements.Statement[T, Err]
-	var err ParseError
-	if p.match(scanner.SEMICOLON) {
-		initializer = nil
-	} else if p.match(scanner.VAR) {
-		initializer, err = p.varDeclaration()
-	} else {
-		initializer, err = p.expressionStatement()
+	if _, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after 'match'."); err != nil {
+		return nil, err
 	}
 
+	value, err := p.expression()
 	if err != nil {
 		return nil, err
 	}
 
-	var condition expressions.Expression[T, Err]
-	if !p.check(scanner.SEMICOLON) {
-		condition, err = p.expression()
-	}
-
-	if err != nil {
+	if _, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after match value."); err != nil {
 		return nil, err
 	}
 
-	if _, err = p.consume(scanner.SEMICOLON, "Expect ';' after loop condition."); err != nil {
+	if _, err = p.consume(scanner.LEFT_BRACE, "Expect '{' before match cases."); err != nil {
 		return nil, err
 	}
 
-	var increment expressions.Expression[T, Err]
-	if !p.check(scanner.RIGHT_PAREN) {
-		increment, err = p.expression()
+	var cases []statements.MatchCase[T, Err]
+	for !p.check(scanner.RIGHT_BRACE) && !p.isAtEnd() {
+		caseKeyword, err := p.consume(scanner.CASE, "Expect 'case' in match body.")
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, err := p.casePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.consume(scanner.ARROW, "Expect '=>' after case pattern."); err != nil {
+			return nil, err
+		}
+
+		body, err := p.caseBody()
+		if err != nil {
+			return nil, err
+		}
+
+		cases = append(cases, statements.NewMatchCase(caseKeyword, pattern, body))
 	}
 
-	if _, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after for clauses."); err != nil {
+	if _, err := p.consume(scanner.RIGHT_BRACE, "Expect '}' after match cases."); err != nil {
 		return nil, err
 	}
 
-	body, err := p.statement()
+	return statements.NewMatch(keyword, value, cases), nil
+}
+
+// Simple case bodies may be terminated by ',' instead of ';' or end at the next case.
+func (p *Parser[T, Err]) caseBody() (statements.Statement[T, Err], ParseError) {
+	for _, tokenType := range []scanner.TokenType{scanner.FOR, scanner.IF, scanner.RETURN, scanner.WHILE, scanner.MATCH, scanner.LEFT_BRACE} {
+		if p.check(tokenType) {
+			body, err := p.statement()
+			p.match(scanner.COMMA)
+			return body, err
+		}
+	}
+
+	line := p.peek().Line
+	isPrint := p.match(scanner.PRINT)
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.match(scanner.COMMA, scanner.SEMICOLON) && !p.check(scanner.CASE) && !p.check(scanner.RIGHT_BRACE) {
+		return nil, NewParseError(p.peek().Line, p.peek().Lexeme, "Expect ',' after case body.")
+	}
+
+	if isPrint {
+		return statements.NewPrintStatement(line, value), nil
+	}
+	return statements.NewExpression(line, value), nil
+}
+
+func (p *Parser[T, Err]) casePattern() (*statements.CasePattern[T, Err], ParseError) {
+	pattern, err := p.singleCasePattern()
+	if err != nil || !p.check(scanner.PIPE) {
+		return pattern, err
+	}
+
+	patterns := []*statements.CasePattern[T, Err]{pattern}
+	for p.match(scanner.PIPE) {
+		if pattern, err = p.singleCasePattern(); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return statements.NewAlternativeCase(patterns), nil
+}
+
+func (p *Parser[T, Err]) singleCasePattern() (*statements.CasePattern[T, Err], ParseError) {
+	if p.match(scanner.FALSE) {
+		return statements.NewLiteralCase[T, Err](p.previous(), false), nil
+	}
+
+	if p.match(scanner.TRUE) {
+		return statements.NewLiteralCase[T, Err](p.previous(), true), nil
+	}
+
+	if p.match(scanner.NIL) {
+		return statements.NewLiteralCase[T, Err](p.previous(), nil), nil
+	}
+
+	if p.match(scanner.NUMBER, scanner.STRING) {
+		return statements.NewLiteralCase[T, Err](p.previous(), p.previous().Literal), nil
+	}
+
+	if p.match(scanner.MINUS) {
+		number, err := p.consume(scanner.NUMBER, "Expect number after '-' in pattern.")
+		if err != nil {
+			return nil, err
+		}
+
+		switch value := number.Literal.(type) {
+		case int64:
+			return statements.NewLiteralCase[T, Err](number, -value), nil
+		case float64:
+			return statements.NewLiteralCase[T, Err](number, -value), nil
+		}
+	}
+
+	if p.match(scanner.IDENTIFIER) {
+		name := p.previous()
+		if name.Lexeme == "_" {
+			return statements.NewWildcardCase[T, Err](name), nil
+		}
+
+		if !p.match(scanner.LEFT_PAREN) {
+			return statements.NewBindingCase[T, Err](name), nil
+		}
+
+		var patterns []*statements.CasePattern[T, Err]
+		if !p.check(scanner.RIGHT_PAREN) {
+			for {
+				pattern, err := p.casePattern()
+				if err != nil {
+					return nil, err
+				}
+				patterns = append(patterns, pattern)
+
+				if !p.match(scanner.COMMA) {
+					break
+				}
+			}
+		}
+
+		if _, err := p.consume(scanner.RIGHT_PAREN, "Expect ')' after class pattern."); err != nil {
+			return nil, err
+		}
+
+		return statements.NewClassCase(expressions.NewVariable[T, Err](name), patterns), nil
+	}
+
+	return nil, NewParseError(p.peek().Line, p.peek().Lexeme, "Expect case pattern.")
+}
+
+func (p *Parser[T, Err]) forStatement() (statements.Statement[T, Err], ParseError) {
+	keyword := p.previous()
+	if _, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after 'for'."); err != nil {
+		return nil, err
+	}
+
+	if p.check(scanner.IDENTIFIER) && p.peekAt(1).Type == scanner.IN ||
+		p.check(scanner.VAR) && p.peekAt(2).Type == scanner.IN {
+		return p.forInStatement(keyword)
+	}
+
+	var initializer statements.Statement[T, Err]
+	var err ParseError
+	if p.match(scanner.SEMICOLON) {
+		initializer = nil
+	} else if p.match(scanner.VAR) {
+		initializer, err = p.varDeclaration()
+	} else {
+		initializer, err = p.expressionStatement()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var condition expressions.Expression[T, Err]
+	if !p.check(scanner.SEMICOLON) {
+		condition, err = p.expression()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = p.consume(scanner.SEMICOLON, "Expect ';' after loop condition."); err != nil {
+		return nil, err
+	}
+
+	var increment expressions.Expression[T, Err]
+	if !p.check(scanner.RIGHT_PAREN) {
+		increment, err = p.expression()
+	}
+
+	if _, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after for clauses."); err != nil {
+		return nil, err
+	}
+
+	body, err := p.statement()
 
 	if increment != nil {
-		blockStatements := []statements.Statement[T, Err]{body, statements.NewExpression(increment)}
-		body = statements.NewBlock(blockStatements)
+		blockStatements := []statements.Statement[T, Err]{body, statements.NewExpression(keyword.Line, increment)}
+		body = statements.NewBlock(keyword.Line, blockStatements)
 	}
 
 	if condition == nil {
-		condition = expressions.NewLiteral[T, Err](true)
+		condition = expressions.NewLiteral[T, Err](keyword, true)
 	}
 
-	body = statements.NewWhile(condition, body)
+	body = statements.NewWhile(keyword.Line, condition, body)
 
 	if initializer != nil {
 		blockStatements := []statements.Statement[T, Err]{initializer, body}
-		body = statements.NewBlock(blockStatements)
+		body = statements.NewBlock(keyword.Line, blockStatements)
 	}
 
 	return body, nil
 }
 
+func (p *Parser[T, Err]) forInStatement(keyword scanner.Token) (statements.Statement[T, Err], ParseError) {
+	p.match(scanner.VAR)
+	name, err := p.consume(scanner.IDENTIFIER, "Expect loop variable name.")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.IN, "Expect 'in' after loop variable."); err != nil {
+		return nil, err
+	}
+
+	iterable, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = p.consume(scanner.RIGHT_PAREN, "Expect ')' after for clauses."); err != nil {
+		return nil, err
+	}
+
+	body, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewForIn(keyword, name, iterable, body), nil
+}
+
+func (p *Parser[T, Err]) yieldStatement() (statements.Statement[T, Err], ParseError) {
+	keyword := p.previous()
+	p.yielded = true
+
+	var value expressions.Expression[T, Err]
+	if !p.check(scanner.SEMICOLON) {
+		var err ParseError
+		if value, err = p.expression(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.consume(scanner.SEMICOLON, "Expect ';' after yield value."); err != nil {
+		return nil, err
+	}
+
+	return statements.NewYield(keyword, value), nil
+}
+
 func (p *Parser[T, Err]) returnStatement() (statements.Statement[T, Err], ParseError) {
 	keyword := p.previous()
 
@@ -488,11 +1020,15 @@ func (p *Parser[T, Err]) returnStatement() (statements.Statement[T, Err], ParseE
 	var err ParseError
 
 	if !p.check(scanner.SEMICOLON) {
-		value, err = p.expression()
-	}
+		var values []expressions.Expression[T, Err]
+		if values, err = p.expressionList(); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
+		value = values[0]
+		if len(values) > 1 {
+			value = expressions.NewList(keyword, values)
+		}
 	}
 
 	if _, err := p.consume(scanner.SEMICOLON, "Expect ';' after return value."); err != nil {
@@ -523,6 +1059,14 @@ func (p *Parser[T, Err]) statement() (statements.Statement[T, Err], ParseError)
 		return p.whileStatement()
 	}
 
+	if p.match(scanner.MATCH) {
+		return p.matchStatement()
+	}
+
+	if p.match(scanner.YIELD) {
+		return p.yieldStatement()
+	}
+
 	if p.match(scanner.LEFT_BRACE) {
 		return p.blockStatement()
 	}
@@ -532,6 +1076,10 @@ func (p *Parser[T, Err]) statement() (statements.Statement[T, Err], ParseError)
 }
 
 func (p *Parser[T, Err]) varDeclaration() (statements.Statement[T, Err], ParseError) {
+	if p.check(scanner.LEFT_BRACKET) || p.check(scanner.LEFT_BRACE) {
+		return p.destructureDeclaration(false)
+	}
+
 	name, consumeEr := p.consume(scanner.IDENTIFIER, "Expect variable name.")
 
 	if consumeEr != nil {
@@ -555,6 +1103,82 @@ func (p *Parser[T, Err]) varDeclaration() (statements.Statement[T, Err], ParseEr
 	}
 }
 
+func (p *Parser[T, Err]) constDeclaration() (statements.Statement[T, Err], ParseError) {
+	if p.check(scanner.LEFT_BRACKET) || p.check(scanner.LEFT_BRACE) {
+		return p.destructureDeclaration(true)
+	}
+
+	name, err := p.consume(scanner.IDENTIFIER, "Expect constant name.")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.EQUAL, "Expect '=' after constant name."); err != nil {
+		return nil, err
+	}
+
+	initializer, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.SEMICOLON, "Expect ';' after constant declaration."); err != nil {
+		return nil, err
+	}
+
+	return statements.NewConstant(name, initializer), nil
+}
+
+func (p *Parser[T, Err]) destructureDeclaration(constant bool) (statements.Statement[T, Err], ParseError) {
+	pattern, err := p.pattern()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.EQUAL, "Expect '=' after destructuring pattern."); err != nil {
+		return nil, err
+	}
+
+	initializer, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.SEMICOLON, "Expect ';' after variable declaration."); err != nil {
+		return nil, err
+	}
+
+	return statements.NewDestructure(pattern, initializer, constant), nil
+}
+
+func (p *Parser[T, Err]) pattern() (*statements.Pattern, ParseError) {
+	brace := p.advance()
+
+	kind, closing, lexeme := statements.LIST_PATTERN, scanner.TokenType(scanner.RIGHT_BRACKET), "]"
+	if brace.Type == scanner.LEFT_BRACE {
+		kind, closing, lexeme = statements.OBJECT_PATTERN, scanner.RIGHT_BRACE, "}"
+	}
+
+	var names []scanner.Token
+	for {
+		name, err := p.consume(scanner.IDENTIFIER, "Expect variable name in pattern.")
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+
+		if !p.match(scanner.COMMA) {
+			break
+		}
+	}
+
+	if _, err := p.consume(closing, "Expect '"+lexeme+"' after pattern."); err != nil {
+		return nil, err
+	}
+
+	return statements.NewPattern(kind, brace, names), nil
+}
+
 func (p *Parser[T, Err]) function(kind interpeter.FunctionType) (*statements.Function[T, Err], ParseError) {
 	name, err := p.consume(scanner.IDENTIFIER, fmt.Sprintf("Expect %s name.", kind))
 	if err != nil {
@@ -565,6 +1189,10 @@ func (p *Parser[T, Err]) function(kind interpeter.FunctionType) (*statements.Fun
 		return nil, err
 	}
 
+	return p.functionBody(kind, name)
+}
+
+func (p *Parser[T, Err]) parameters(kind interpeter.FunctionType) ([]scanner.Token, ParseError) {
 	var params []scanner.Token
 	hasParamArg := !p.check(scanner.RIGHT_PAREN)
 	for hasParamArg {
@@ -585,16 +1213,137 @@ func (p *Parser[T, Err]) function(kind interpeter.FunctionType) (*statements.Fun
 		return nil, err
 	}
 
+	return params, nil
+}
+
+// Parses a function declared after the async keyword.
+func (p *Parser[T, Err]) asyncFunction(kind interpeter.FunctionType) (*statements.Function[T, Err], ParseError) {
+	function, err := p.function(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if function.Generator {
+		return nil, NewParseError(function.Name.Line, function.Name.Lexeme, "An async function can't yield.")
+	}
+
+	function.Async = true
+	return function, nil
+}
+
+// Parses the parameter list after the opening parenthesis and the block body of a function.
+func (p *Parser[T, Err]) functionBody(kind interpeter.FunctionType, name scanner.Token) (*statements.Function[T, Err], ParseError) {
+	params, err := p.parameters(kind)
+	if err != nil {
+		return nil, err
+	}
+
 	if _, err := p.consume(scanner.LEFT_BRACE, fmt.Sprintf("Expect '{' before %s body.", kind)); err != nil {
 		return nil, err
 	}
 
+	return p.functionBlock(name, params)
+}
+
+// Parses the block of a function body and marks the function as a generator if it yields.
+func (p *Parser[T, Err]) functionBlock(name scanner.Token, params []scanner.Token) (*statements.Function[T, Err], ParseError) {
+	enclosing := p.yielded
+	p.yielded = false
+
 	body, err := p.block()
 	if err != nil {
 		return nil, err
 	}
 
-	return statements.NewFunction(name, params, body), nil
+	function := statements.NewFunction(name, params, body)
+	function.Generator = p.yielded
+	p.yielded = enclosing
+	return function, nil
+}
+
+func lambdaName(keyword scanner.Token) scanner.Token {
+	return scanner.NewToken(scanner.IDENTIFIER, "lambda", nil, keyword.Line, keyword.Column)
+}
+
+// Parses "fun (a, b) { ... }" after the "fun" keyword.
+func (p *Parser[T, Err]) lambda() (expressions.Expression[T, Err], ParseError) {
+	keyword := p.previous()
+
+	if _, err := p.consume(scanner.LEFT_PAREN, "Expect '(' after 'fun'."); err != nil {
+		return nil, err
+	}
+
+	function, err := p.functionBody("lambda", lambdaName(keyword))
+	if err != nil {
+		return nil, err
+	}
+
+	return expressions.NewLambda[T, Err](keyword, function), nil
+}
+
+// Parses "async fun (a, b) { ... }" after the "fun" keyword.
+func (p *Parser[T, Err]) asyncLambda() (expressions.Expression[T, Err], ParseError) {
+	lambda, err := p.lambda()
+	if err != nil {
+		return nil, err
+	}
+
+	function := lambda.(*expressions.Lambda[T, Err]).Function.(*statements.Function[T, Err])
+	if function.Generator {
+		return nil, NewParseError(function.Name.Line, function.Name.Lexeme, "An async function can't yield.")
+	}
+
+	function.Async = true
+	return lambda, nil
+}
+
+// Looks ahead for "(" [IDENTIFIER ("," IDENTIFIER)*] ")" "=>" without consuming anything.
+func (p *Parser[T, Err]) isArrowFunction() bool {
+	if !p.check(scanner.LEFT_PAREN) {
+		return false
+	}
+
+	offset := 1
+	if p.peekAt(offset).Type == scanner.IDENTIFIER {
+		offset += 1
+		for p.peekAt(offset).Type == scanner.COMMA && p.peekAt(offset+1).Type == scanner.IDENTIFIER {
+			offset += 2
+		}
+	}
+
+	return p.peekAt(offset).Type == scanner.RIGHT_PAREN && p.peekAt(offset+1).Type == scanner.ARROW
+}
+
+// Parses "(a, b) => expression" or "(a, b) => { ... }".
+func (p *Parser[T, Err]) arrowFunction() (expressions.Expression[T, Err], ParseError) {
+	parenthesis := p.advance()
+
+	params, err := p.parameters("lambda")
+	if err != nil {
+		return nil, err
+	}
+
+	arrow, err := p.consume(scanner.ARROW, "Expect '=>' after lambda parameters.")
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(scanner.LEFT_BRACE) {
+		function, err := p.functionBlock(lambdaName(parenthesis), params)
+		if err != nil {
+			return nil, err
+		}
+		return expressions.NewLambda[T, Err](parenthesis, function), nil
+	}
+
+	value, err := p.assignment()
+	if err != nil {
+		return nil, err
+	}
+
+	body := []statements.Statement[T, Err]{statements.NewReturn(arrow, value)}
+	function := statements.NewFunction(lambdaName(parenthesis), params, body)
+	return expressions.NewLambda[T, Err](parenthesis, function), nil
 }
 
 func (p *Parser[T, Err]) classDeclaration() (statements.Statement[T, Err], ParseError) {
@@ -603,10 +1352,78 @@ func (p *Parser[T, Err]) classDeclaration() (statements.Statement[T, Err], Parse
 		return nil, err
 	}
 
+	var traits []*expressions.Variable[T, Err]
+	if p.match(scanner.WITH) {
+		for {
+			trait, err := p.consume(scanner.IDENTIFIER, "Expect trait name.")
+			if err != nil {
+				return nil, err
+			}
+			traits = append(traits, expressions.NewVariable[T, Err](trait))
+
+			if !p.match(scanner.COMMA) {
+				break
+			}
+		}
+	}
+
 	if _, err := p.consume(scanner.LEFT_BRACE, "Expect '{' before class body."); err != nil {
 		return nil, err
 	}
 
+	var methods, staticMethods, getters, setters []statements.Function[T, Err]
+	for !p.check(scanner.RIGHT_BRACE) && !p.isAtEnd() {
+		if p.match(scanner.CLASS) {
+			if declaration, err := p.function(interpeter.METHOD); err != nil {
+				return nil, err
+			} else {
+				staticMethods = append(staticMethods, *declaration)
+			}
+		} else if p.check(scanner.IDENTIFIER) && p.peek().Lexeme == "set" && p.peekAt(1).Type == scanner.IDENTIFIER {
+			p.advance()
+			if declaration, err := p.function(interpeter.METHOD); err != nil {
+				return nil, err
+			} else if len(declaration.Params) != 1 {
+				return nil, NewParseError(declaration.Name.Line, declaration.Name.Lexeme, "A setter must have exactly one parameter.")
+			} else {
+				setters = append(setters, *declaration)
+			}
+		} else if p.match(scanner.ASYNC) {
+			if declaration, err := p.asyncFunction(interpeter.METHOD); err != nil {
+				return nil, err
+			} else {
+				methods = append(methods, *declaration)
+			}
+		} else if p.check(scanner.IDENTIFIER) && p.peekAt(1).Type == scanner.LEFT_BRACE {
+			if declaration, err := p.getter(); err != nil {
+				return nil, err
+			} else {
+				getters = append(getters, *declaration)
+			}
+		} else if declaration, err := p.function(interpeter.METHOD); err != nil {
+			return nil, err
+		} else {
+			methods = append(methods, *declaration)
+		}
+	}
+
+	if _, err := p.consume(scanner.RIGHT_BRACE, "Expect '}' after class body."); err != nil {
+		return nil, err
+	}
+
+	return statements.NewClass(name, traits, methods, staticMethods, getters, setters), nil
+}
+
+func (p *Parser[T, Err]) traitDeclaration() (statements.Statement[T, Err], ParseError) {
+	name, err := p.consume(scanner.IDENTIFIER, "Expect trait name.")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(scanner.LEFT_BRACE, "Expect '{' before trait body."); err != nil {
+		return nil, err
+	}
+
 	var methods []statements.Function[T, Err]
 	for !p.check(scanner.RIGHT_BRACE) && !p.isAtEnd() {
 		if declaration, err := p.function(interpeter.METHOD); err != nil {
@@ -616,11 +1433,22 @@ func (p *Parser[T, Err]) classDeclaration() (statements.Statement[T, Err], Parse
 		}
 	}
 
-	if _, err := p.consume(scanner.RIGHT_BRACE, "Expect '}' after class body."); err != nil {
+	if _, err := p.consume(scanner.RIGHT_BRACE, "Expect '}' after trait body."); err != nil {
+		return nil, err
+	}
+
+	return statements.NewTrait(name, methods), nil
+}
+
+// Parses a getter, which is a method declared without a parameter list.
+func (p *Parser[T, Err]) getter() (*statements.Function[T, Err], ParseError) {
+	name := p.advance()
+
+	if _, err := p.consume(scanner.LEFT_BRACE, "Expect '{' before getter body."); err != nil {
 		return nil, err
 	}
 
-	return statements.NewClass(name, methods), nil
+	return p.functionBlock(name, nil)
 }
 
 func (p *Parser[T, Err]) declaration() (statements.Statement[T, Err], ParseError) {
@@ -629,10 +1457,19 @@ func (p *Parser[T, Err]) declaration() (statements.Statement[T, Err], ParseError
 
 	if p.match(scanner.CLASS) {
 		return p.classDeclaration()
+	} else if p.match(scanner.TRAIT) {
+		return p.traitDeclaration()
 	} else if p.match(scanner.VAR) {
 		value, err = p.varDeclaration()
-	} else if p.match(scanner.FUN) {
+	} else if p.match(scanner.CONST) {
+		value, err = p.constDeclaration()
+	} else if p.check(scanner.FUN) && p.peekAt(1).Type != scanner.LEFT_PAREN {
+		p.advance()
 		return p.function("function")
+	} else if p.check(scanner.ASYNC) && p.peekAt(1).Type == scanner.FUN && p.peekAt(2).Type != scanner.LEFT_PAREN {
+		p.advance()
+		p.advance()
+		return p.asyncFunction("function")
 	} else if p.match(scanner.VAR) {
 		value, err = p.varDeclaration()
 	} else {
@@ -647,6 +1484,16 @@ func (p *Parser[T, Err]) declaration() (statements.Statement[T, Err], ParseError
 	return value, nil
 }
 
+// Property names may also be the 'yield' keyword, as in Fiber.yield().
+func (p *Parser[T, Err]) propertyName(message string) (scanner.Token, ParseError) {
+	if p.match(scanner.YIELD) {
+		name := p.previous()
+		return scanner.NewToken(scanner.IDENTIFIER, name.Lexeme, nil, name.Line, name.Column), nil
+	}
+
+	return p.consume(scanner.IDENTIFIER, message)
+}
+
 func (p *Parser[T, Err]) finishCall(expr expressions.Expression[T, Err]) (expressions.Expression[T, Err], ParseError) {
 	var args []expressions.Expression[T, Err]
 
@@ -679,6 +1526,7 @@ func (p *Parser[T, Err]) call() (expressions.Expression[T, Err], ParseError) {
 		return nil, err
 	}
 
+	optional := false
 	for {
 		if p.match(scanner.LEFT_PAREN) {
 			expr, err = p.finishCall(expr)
@@ -687,18 +1535,44 @@ func (p *Parser[T, Err]) call() (expressions.Expression[T, Err], ParseError) {
 				return nil, err
 			}
 		} else if p.match(scanner.DOT) {
-			name, err := p.consume(scanner.IDENTIFIER, "Expect class name.")
+			name, err := p.propertyName("Expect class name.")
 
 			if err != nil {
 				return nil, err
 			}
 
 			expr = expressions.NewGet(expr, name)
+		} else if p.match(scanner.QUESTION_DOT) {
+			name, err := p.propertyName("Expect property name after '?.'.")
+
+			if err != nil {
+				return nil, err
+			}
+
+			optional = true
+			expr = expressions.NewOptionalGet(expr, name)
+		} else if p.match(scanner.LEFT_BRACKET) {
+			bracket := p.previous()
+			index, err := p.expression()
+
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := p.consume(scanner.RIGHT_BRACKET, "Expect ']' after index."); err != nil {
+				return nil, err
+			}
+
+			expr = expressions.NewIndex(expr, bracket, index)
 		} else {
 			break
 		}
 	}
 
+	if optional {
+		return expressions.NewOptionalChain(expr), nil
+	}
+
 	return expr, err
 }
 
@@ -717,3 +1591,16 @@ func (p *Parser[T, Err]) Parse() ([]statements.Statement[T, Err], ParseError) {
 
 	return stmnts, nil
 }
+
+func (p *Parser[T, Err]) ParseExpression() (expressions.Expression[T, Err], ParseError) {
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isAtEnd() {
+		return nil, NewParseError(p.peek().Line, p.peek().Lexeme, "Expect end of expression.")
+	}
+
+	return expr, nil
+}
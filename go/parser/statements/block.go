@@ -4,14 +4,20 @@ type Block[T any, Err error] struct {
 	Statement[T, Err]
 
 	Statements []Statement[T, Err]
+	SourceLine int
 }
 
-func NewBlock[T any, Err error](statements []Statement[T, Err]) *Block[T, Err] {
+func NewBlock[T any, Err error](line int, statements []Statement[T, Err]) *Block[T, Err] {
 	return &Block[T, Err]{
 		Statements: statements,
+		SourceLine: line,
 	}
 }
 
+func (e *Block[T, Err]) Line() int {
+	return e.SourceLine
+}
+
 func (e *Block[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitBlockStatement(e)
 }
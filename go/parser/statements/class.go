@@ -8,18 +8,30 @@ import (
 type Class[T any, Err error] struct {
 	Statement[T, Err]
 
-	Name    scanner.Token
-	Super   expressions.Variable[T, Err]
-	Methods []Function[T, Err]
+	Name          scanner.Token
+	Super         expressions.Variable[T, Err]
+	Traits        []*expressions.Variable[T, Err]
+	Methods       []Function[T, Err]
+	StaticMethods []Function[T, Err]
+	Getters       []Function[T, Err]
+	Setters       []Function[T, Err]
 }
 
-func NewClass[T any, Err error](name scanner.Token, methods []Function[T, Err]) *Class[T, Err] {
+func NewClass[T any, Err error](name scanner.Token, traits []*expressions.Variable[T, Err], methods []Function[T, Err], staticMethods []Function[T, Err], getters []Function[T, Err], setters []Function[T, Err]) *Class[T, Err] {
 	return &Class[T, Err]{
-		Name:    name,
-		Methods: methods,
+		Name:          name,
+		Traits:        traits,
+		Methods:       methods,
+		StaticMethods: staticMethods,
+		Getters:       getters,
+		Setters:       setters,
 	}
 }
 
 func (e *Class[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitClassStatement(e)
 }
+
+func (e *Class[T, Err]) Line() int {
+	return e.Name.Line
+}
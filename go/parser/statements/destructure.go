@@ -0,0 +1,51 @@
+package statements
+
+import (
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type PatternKind string
+
+const (
+	LIST_PATTERN   PatternKind = "list"
+	OBJECT_PATTERN PatternKind = "object"
+)
+
+type Pattern struct {
+	Kind  PatternKind
+	Brace scanner.Token
+	Names []scanner.Token
+}
+
+func NewPattern(kind PatternKind, brace scanner.Token, names []scanner.Token) *Pattern {
+	return &Pattern{
+		Kind:  kind,
+		Brace: brace,
+		Names: names,
+	}
+}
+
+type Destructure[T any, Err error] struct {
+	Statement[T, Err]
+
+	Pattern     *Pattern
+	Initializer expressions.Expression[T, Err]
+	Constant    bool
+}
+
+func NewDestructure[T any, Err error](pattern *Pattern, initializer expressions.Expression[T, Err], constant bool) *Destructure[T, Err] {
+	return &Destructure[T, Err]{
+		Pattern:     pattern,
+		Initializer: initializer,
+		Constant:    constant,
+	}
+}
+
+func (e *Destructure[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitDestructureStatement(e)
+}
+
+func (e *Destructure[T, Err]) Line() int {
+	return e.Pattern.Brace.Line
+}
@@ -7,15 +7,21 @@ import (
 type Expression[T any, Err error] struct {
 	Statement[T, Err]
 
-	Exp expressions.Expression[T, Err]
+	Exp        expressions.Expression[T, Err]
+	SourceLine int
 }
 
-func NewExpression[T any, Err error](exp expressions.Expression[T, Err]) *Expression[T, Err] {
+func NewExpression[T any, Err error](line int, exp expressions.Expression[T, Err]) *Expression[T, Err] {
 	return &Expression[T, Err]{
-		Exp: exp,
+		Exp:        exp,
+		SourceLine: line,
 	}
 }
 
+func (e *Expression[T, Err]) Line() int {
+	return e.SourceLine
+}
+
 func (e *Expression[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitExpressionStatement(e)
 }
@@ -0,0 +1,32 @@
+package statements
+
+import (
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type ForIn[T any, Err error] struct {
+	Statement[T, Err]
+
+	Keyword  scanner.Token
+	Name     scanner.Token
+	Iterable expressions.Expression[T, Err]
+	Body     Statement[T, Err]
+}
+
+func NewForIn[T any, Err error](keyword scanner.Token, name scanner.Token, iterable expressions.Expression[T, Err], body Statement[T, Err]) *ForIn[T, Err] {
+	return &ForIn[T, Err]{
+		Keyword:  keyword,
+		Name:     name,
+		Iterable: iterable,
+		Body:     body,
+	}
+}
+
+func (e *ForIn[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitForInStatement(e)
+}
+
+func (e *ForIn[T, Err]) Line() int {
+	return e.Keyword.Line
+}
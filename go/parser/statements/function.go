@@ -10,6 +10,11 @@ type Function[T any, Err error] struct {
 	Name   scanner.Token
 	Params []scanner.Token
 	Body   []Statement[T, Err]
+
+	// Set when the body contains a yield, calling the function then returns a generator.
+	Generator bool
+	// Async functions run on their own fiber and return a promise when called.
+	Async bool
 }
 
 func NewFunction[T any, Err error](name scanner.Token, params []scanner.Token, body []Statement[T, Err]) *Function[T, Err] {
@@ -23,3 +28,7 @@ func NewFunction[T any, Err error](name scanner.Token, params []scanner.Token, b
 func (e *Function[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitFunctionStatement(e)
 }
+
+func (e *Function[T, Err]) Line() int {
+	return e.Name.Line
+}
@@ -8,18 +8,24 @@ type If[T any, Err error] struct {
 	Condition  expressions.Expression[T, Err]
 	IfBranch   Statement[T, Err]
 	ElseBranch Statement[T, Err]
+	SourceLine int
 }
 
 func NewIf[T any, Err error](
+	line int,
 	condition expressions.Expression[T, Err],
 	ifBranch Statement[T, Err],
 	elseBranch Statement[T, Err],
 ) *If[T, Err] {
 	return &If[T, Err]{
-		Condition: condition, IfBranch: ifBranch, ElseBranch: elseBranch,
+		Condition: condition, IfBranch: ifBranch, ElseBranch: elseBranch, SourceLine: line,
 	}
 }
 
+func (e *If[T, Err]) Line() int {
+	return e.SourceLine
+}
+
 func (e *If[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitIfStatement(e)
 }
@@ -0,0 +1,87 @@
+package statements
+
+import (
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type CasePatternKind string
+
+const (
+	LITERAL_CASE     CasePatternKind = "literal"
+	WILDCARD_CASE    CasePatternKind = "wildcard"
+	BINDING_CASE     CasePatternKind = "binding"
+	CLASS_CASE       CasePatternKind = "class"
+	ALTERNATIVE_CASE CasePatternKind = "alternative"
+)
+
+type CasePattern[T any, Err error] struct {
+	Kind     CasePatternKind
+	Token    scanner.Token
+	Value    any
+	Class    *expressions.Variable[T, Err]
+	Patterns []*CasePattern[T, Err]
+}
+
+func NewLiteralCase[T any, Err error](token scanner.Token, value any) *CasePattern[T, Err] {
+	return &CasePattern[T, Err]{Kind: LITERAL_CASE, Token: token, Value: value}
+}
+
+func NewWildcardCase[T any, Err error](token scanner.Token) *CasePattern[T, Err] {
+	return &CasePattern[T, Err]{Kind: WILDCARD_CASE, Token: token}
+}
+
+func NewBindingCase[T any, Err error](name scanner.Token) *CasePattern[T, Err] {
+	return &CasePattern[T, Err]{Kind: BINDING_CASE, Token: name}
+}
+
+func NewClassCase[T any, Err error](class *expressions.Variable[T, Err], patterns []*CasePattern[T, Err]) *CasePattern[T, Err] {
+	return &CasePattern[T, Err]{Kind: CLASS_CASE, Token: class.Name, Class: class, Patterns: patterns}
+}
+
+func NewAlternativeCase[T any, Err error](patterns []*CasePattern[T, Err]) *CasePattern[T, Err] {
+	return &CasePattern[T, Err]{Kind: ALTERNATIVE_CASE, Token: patterns[0].Token, Patterns: patterns}
+}
+
+// Matches every value without inspecting it.
+func (p *CasePattern[T, Err]) IsIrrefutable() bool {
+	return p.Kind == WILDCARD_CASE || p.Kind == BINDING_CASE
+}
+
+type MatchCase[T any, Err error] struct {
+	Keyword scanner.Token
+	Pattern *CasePattern[T, Err]
+	Body    Statement[T, Err]
+}
+
+func NewMatchCase[T any, Err error](keyword scanner.Token, pattern *CasePattern[T, Err], body Statement[T, Err]) MatchCase[T, Err] {
+	return MatchCase[T, Err]{
+		Keyword: keyword,
+		Pattern: pattern,
+		Body:    body,
+	}
+}
+
+type Match[T any, Err error] struct {
+	Statement[T, Err]
+
+	Keyword scanner.Token
+	Value   expressions.Expression[T, Err]
+	Cases   []MatchCase[T, Err]
+}
+
+func NewMatch[T any, Err error](keyword scanner.Token, value expressions.Expression[T, Err], cases []MatchCase[T, Err]) *Match[T, Err] {
+	return &Match[T, Err]{
+		Keyword: keyword,
+		Value:   value,
+		Cases:   cases,
+	}
+}
+
+func (e *Match[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitMatchStatement(e)
+}
+
+func (e *Match[T, Err]) Line() int {
+	return e.Keyword.Line
+}
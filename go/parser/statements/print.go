@@ -7,15 +7,21 @@ import (
 type Print[T any, Err error] struct {
 	Statement[T, Err]
 
-	Exp expressions.Expression[T, Err]
+	Exp        expressions.Expression[T, Err]
+	SourceLine int
 }
 
-func NewPrintStatement[T any, Err error](exp expressions.Expression[T, Err]) *Print[T, Err] {
+func NewPrintStatement[T any, Err error](line int, exp expressions.Expression[T, Err]) *Print[T, Err] {
 	return &Print[T, Err]{
-		Exp: exp,
+		Exp:        exp,
+		SourceLine: line,
 	}
 }
 
+func (e *Print[T, Err]) Line() int {
+	return e.SourceLine
+}
+
 func (e *Print[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitPrintStatement(e)
 }
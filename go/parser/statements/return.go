@@ -22,3 +22,7 @@ func NewReturn[T any, Err error](keyword scanner.Token, value expressions.Expres
 func (e *Return[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitReturnStatement(e)
 }
+
+func (e *Return[T, Err]) Line() int {
+	return e.Keyword.Line
+}
@@ -0,0 +1,27 @@
+package statements
+
+import (
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Trait[T any, Err error] struct {
+	Statement[T, Err]
+
+	Name    scanner.Token
+	Methods []Function[T, Err]
+}
+
+func NewTrait[T any, Err error](name scanner.Token, methods []Function[T, Err]) *Trait[T, Err] {
+	return &Trait[T, Err]{
+		Name:    name,
+		Methods: methods,
+	}
+}
+
+func (e *Trait[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitTraitStatement(e)
+}
+
+func (e *Trait[T, Err]) Line() int {
+	return e.Name.Line
+}
@@ -10,6 +10,7 @@ type Var[T any, Err error] struct {
 
 	Name        scanner.Token
 	Initializer expressions.Expression[T, Err]
+	Constant    bool
 }
 
 func NewVar[T any, Err error](name scanner.Token, initializer expressions.Expression[T, Err]) *Var[T, Err] {
@@ -18,6 +19,16 @@ func NewVar[T any, Err error](name scanner.Token, initializer expressions.Expres
 	}
 }
 
+func NewConstant[T any, Err error](name scanner.Token, initializer expressions.Expression[T, Err]) *Var[T, Err] {
+	return &Var[T, Err]{
+		Name: name, Initializer: initializer, Constant: true,
+	}
+}
+
 func (e *Var[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitVarStatement(e)
 }
+
+func (e *Var[T, Err]) Line() int {
+	return e.Name.Line
+}
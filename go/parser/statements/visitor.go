@@ -10,4 +10,9 @@ type Visitor[T any, Err error] interface {
 	VisitFunctionStatement(exp *Function[T, Err]) (T, Err)
 	VisitReturnStatement(exp *Return[T, Err]) (T, Err)
 	VisitClassStatement(exp *Class[T, Err]) (T, Err)
+	VisitTraitStatement(exp *Trait[T, Err]) (T, Err)
+	VisitDestructureStatement(exp *Destructure[T, Err]) (T, Err)
+	VisitMatchStatement(exp *Match[T, Err]) (T, Err)
+	VisitForInStatement(exp *ForIn[T, Err]) (T, Err)
+	VisitYieldStatement(exp *Yield[T, Err]) (T, Err)
 }
@@ -5,19 +5,25 @@ import "github.com/lukas-reining/lox/parser/expressions"
 type While[T any, Err error] struct {
 	Statement[T, Err]
 
-	Condition expressions.Expression[T, Err]
-	Body      Statement[T, Err]
+	Condition  expressions.Expression[T, Err]
+	Body       Statement[T, Err]
+	SourceLine int
 }
 
 func NewWhile[T any, Err error](
+	line int,
 	condition expressions.Expression[T, Err],
 	body Statement[T, Err],
 ) *While[T, Err] {
 	return &While[T, Err]{
-		Condition: condition, Body: body,
+		Condition: condition, Body: body, SourceLine: line,
 	}
 }
 
+func (e *While[T, Err]) Line() int {
+	return e.SourceLine
+}
+
 func (e *While[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
 	return visitor.VisitWhileStatement(e)
 }
@@ -0,0 +1,28 @@
+package statements
+
+import (
+	"github.com/lukas-reining/lox/parser/expressions"
+	"github.com/lukas-reining/lox/scanner"
+)
+
+type Yield[T any, Err error] struct {
+	Statement[T, Err]
+
+	Keyword scanner.Token
+	Value   expressions.Expression[T, Err]
+}
+
+func NewYield[T any, Err error](keyword scanner.Token, value expressions.Expression[T, Err]) *Yield[T, Err] {
+	return &Yield[T, Err]{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
+
+func (e *Yield[T, Err]) Accept(visitor Visitor[T, Err]) (T, Err) {
+	return visitor.VisitYieldStatement(e)
+}
+
+func (e *Yield[T, Err]) Line() int {
+	return e.Keyword.Line
+}
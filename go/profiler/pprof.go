@@ -0,0 +1,187 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Field numbers of the messages in pprof's profile.proto.
+const (
+	PROFILE_SAMPLE_TYPE    = 1
+	PROFILE_SAMPLE         = 2
+	PROFILE_LOCATION       = 4
+	PROFILE_FUNCTION       = 5
+	PROFILE_STRING_TABLE   = 6
+	PROFILE_TIME_NANOS     = 9
+	PROFILE_DURATION_NANOS = 10
+	PROFILE_PERIOD_TYPE    = 11
+	PROFILE_PERIOD         = 12
+
+	VALUE_TYPE_TYPE = 1
+	VALUE_TYPE_UNIT = 2
+
+	SAMPLE_LOCATION_ID = 1
+	SAMPLE_VALUE       = 2
+
+	LOCATION_ID   = 1
+	LOCATION_LINE = 4
+
+	LINE_FUNCTION_ID = 1
+	LINE_LINE        = 2
+
+	FUNCTION_ID         = 1
+	FUNCTION_NAME       = 2
+	FUNCTION_FILENAME   = 4
+	FUNCTION_START_LINE = 5
+)
+
+// A minimal protocol buffer encoder, enough for writing profiles.
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) varint(value uint64) {
+	for value >= 0x80 {
+		b.data = append(b.data, byte(value)|0x80)
+		value >>= 7
+	}
+	b.data = append(b.data, byte(value))
+}
+
+func (b *protoBuffer) key(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protoBuffer) int(field int, value int64) {
+	if value == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(uint64(value))
+}
+
+func (b *protoBuffer) bytes(field int, value []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(value)))
+	b.data = append(b.data, value...)
+}
+
+func (b *protoBuffer) packed(field int, values []int64) {
+	var packed protoBuffer
+	for _, value := range values {
+		packed.varint(uint64(value))
+	}
+	b.bytes(field, packed.data)
+}
+
+func (b *protoBuffer) message(field int, encode func(message *protoBuffer)) {
+	var message protoBuffer
+	encode(&message)
+	b.bytes(field, message.data)
+}
+
+// Writes the exclusive time of each call stack as a gzipped pprof profile. Every function
+// becomes one location, so the profile has function but no line granularity.
+func (p *Profiler) WritePprof(w io.Writer, filename string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stringTable := []string{""}
+	stringIndex := map[string]int64{"": 0}
+	intern := func(value string) int64 {
+		index, ok := stringIndex[value]
+		if !ok {
+			index = int64(len(stringTable))
+			stringTable = append(stringTable, value)
+			stringIndex[value] = index
+		}
+		return index
+	}
+
+	var profile protoBuffer
+	valueType := func(field int, typ string, unit string) {
+		profile.message(field, func(message *protoBuffer) {
+			message.int(VALUE_TYPE_TYPE, intern(typ))
+			message.int(VALUE_TYPE_UNIT, intern(unit))
+		})
+	}
+	valueType(PROFILE_SAMPLE_TYPE, "wall", "nanoseconds")
+
+	var stacks []string
+	for stack := range p.stacks {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+
+	locations := map[string]int64{}
+	var labels []string
+	for _, stack := range stacks {
+		frames := strings.Split(stack, ";")
+
+		var ids []int64
+		for index := len(frames) - 1; index >= 0; index-- {
+			id, ok := locations[frames[index]]
+			if !ok {
+				id = int64(len(labels) + 1)
+				locations[frames[index]] = id
+				labels = append(labels, frames[index])
+			}
+			ids = append(ids, id)
+		}
+
+		profile.message(PROFILE_SAMPLE, func(message *protoBuffer) {
+			message.packed(SAMPLE_LOCATION_ID, ids)
+			message.packed(SAMPLE_VALUE, []int64{p.stacks[stack].Nanoseconds()})
+		})
+	}
+
+	for index, label := range labels {
+		id := int64(index + 1)
+		line := int64(p.labelLine(label))
+		profile.message(PROFILE_LOCATION, func(message *protoBuffer) {
+			message.int(LOCATION_ID, id)
+			message.message(LOCATION_LINE, func(entry *protoBuffer) {
+				entry.int(LINE_FUNCTION_ID, id)
+				entry.int(LINE_LINE, line)
+			})
+		})
+	}
+
+	for index, label := range labels {
+		id := int64(index + 1)
+		line := int64(p.labelLine(label))
+		profile.message(PROFILE_FUNCTION, func(message *protoBuffer) {
+			message.int(FUNCTION_ID, id)
+			message.int(FUNCTION_NAME, intern(label))
+			message.int(FUNCTION_FILENAME, intern(filename))
+			message.int(FUNCTION_START_LINE, line)
+		})
+	}
+
+	profile.int(PROFILE_TIME_NANOS, p.started.UnixNano())
+	profile.int(PROFILE_DURATION_NANOS, p.duration.Nanoseconds())
+	valueType(PROFILE_PERIOD_TYPE, "wall", "nanoseconds")
+	profile.int(PROFILE_PERIOD, 1)
+
+	// The string table is written last, once every string has been interned.
+	for _, value := range stringTable {
+		profile.bytes(PROFILE_STRING_TABLE, []byte(value))
+	}
+
+	compressed := gzip.NewWriter(w)
+	if _, err := compressed.Write(profile.data); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+func (p *Profiler) labelLine(label string) int {
+	for _, function := range p.functions {
+		if function.Label() == label {
+			return function.Line
+		}
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+package profiler
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser/statements"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The pseudo function that top-level code is accounted to.
+const SCRIPT_FUNCTION = "<script>"
+
+type FunctionStats struct {
+	Name  string
+	Line  int
+	Calls int
+	// Time from entering until leaving the function, not counted again for recursive calls.
+	Inclusive time.Duration
+	// Time spent running the function's own statements.
+	Exclusive time.Duration
+}
+
+func (s *FunctionStats) Label() string {
+	if s.Line == 0 {
+		return s.Name
+	}
+	return fmt.Sprintf("%s:%d", s.Name, s.Line)
+}
+
+type LineStats struct {
+	Line int
+	Hits int
+}
+
+type activeCall struct {
+	function *FunctionStats
+	started  time.Time
+	// The labels of all frames from the outermost to this call.
+	stack []string
+}
+
+// The Profiler is a hook that records call counts and timings per function and hit counts
+// per line. Time between two hook events is charged to the function on top of the stack of the
+// interpreter that fires the second event, which keeps generators and fibers, that run on
+// interpreters of their own, from being counted twice.
+type Profiler struct {
+	interpeter.BaseHook
+
+	mutex     sync.Mutex
+	started   time.Time
+	duration  time.Duration
+	lastEvent time.Time
+
+	script    *FunctionStats
+	functions map[string]*FunctionStats
+	lines     map[int]int
+	stacks    map[string]time.Duration
+	active    map[*interpeter.Interpreter][]*activeCall
+}
+
+func NewProfiler() *Profiler {
+	script := &FunctionStats{Name: SCRIPT_FUNCTION, Calls: 1}
+	return &Profiler{
+		script:    script,
+		functions: map[string]*FunctionStats{SCRIPT_FUNCTION: script},
+		lines:     map[int]int{},
+		stacks:    map[string]time.Duration{},
+		active:    map[*interpeter.Interpreter][]*activeCall{},
+	}
+}
+
+func (p *Profiler) Start() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.started = time.Now()
+	p.lastEvent = p.started
+}
+
+func (p *Profiler) Stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	p.script.Exclusive += now.Sub(p.lastEvent)
+	p.stacks[SCRIPT_FUNCTION] += now.Sub(p.lastEvent)
+	p.duration = now.Sub(p.started)
+	p.script.Inclusive = p.duration
+}
+
+// Charges the time since the last event to the function running on the interpreter.
+func (p *Profiler) charge(interpreter *interpeter.Interpreter, now time.Time) {
+	elapsed := now.Sub(p.lastEvent)
+	p.lastEvent = now
+
+	calls := p.active[interpreter]
+	if len(calls) == 0 {
+		p.script.Exclusive += elapsed
+		p.stacks[SCRIPT_FUNCTION] += elapsed
+		return
+	}
+
+	call := calls[len(calls)-1]
+	call.function.Exclusive += elapsed
+	p.stacks[strings.Join(call.stack, ";")] += elapsed
+}
+
+func (p *Profiler) BeforeStatement(interpreter *interpeter.Interpreter, statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) interpeter.RuntimeError {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.charge(interpreter, time.Now())
+	p.lines[statement.Line()]++
+	return nil
+}
+
+func (p *Profiler) EnterFunction(interpreter *interpeter.Interpreter, frame *interpeter.CallFrame) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	p.charge(interpreter, now)
+
+	function := p.function(frame.Name, frame.Line)
+	function.Calls++
+
+	var stack []string
+	for _, caller := range interpreter.Frames() {
+		if caller.Name == SCRIPT_FUNCTION {
+			stack = append(stack, SCRIPT_FUNCTION)
+		} else {
+			stack = append(stack, p.function(caller.Name, caller.Line).Label())
+		}
+	}
+
+	p.active[interpreter] = append(p.active[interpreter], &activeCall{function: function, started: now, stack: stack})
+}
+
+func (p *Profiler) ExitFunction(interpreter *interpeter.Interpreter, frame *interpeter.CallFrame, value interpeter.LoxValue, err interpeter.RuntimeError) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	p.charge(interpreter, now)
+
+	calls := p.active[interpreter]
+	if len(calls) == 0 {
+		return
+	}
+
+	call := calls[len(calls)-1]
+	calls = calls[:len(calls)-1]
+	if len(calls) == 0 {
+		delete(p.active, interpreter)
+	} else {
+		p.active[interpreter] = calls
+	}
+
+	for _, outer := range calls {
+		if outer.function == call.function {
+			return
+		}
+	}
+	call.function.Inclusive += now.Sub(call.started)
+}
+
+func (p *Profiler) function(name string, line int) *FunctionStats {
+	key := fmt.Sprintf("%s:%d", name, line)
+	function, ok := p.functions[key]
+	if !ok {
+		function = &FunctionStats{Name: name, Line: line}
+		p.functions[key] = function
+	}
+	return function
+}
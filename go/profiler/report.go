@@ -0,0 +1,115 @@
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// The number of lines listed in the report.
+const REPORTED_LINES = 20
+
+// Returns the functions that were called, the ones with the most exclusive time first.
+func (p *Profiler) Functions() []FunctionStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var functions []FunctionStats
+	for _, function := range p.functions {
+		if function.Calls > 0 {
+			functions = append(functions, *function)
+		}
+	}
+
+	sort.Slice(functions, func(a, b int) bool {
+		if functions[a].Exclusive != functions[b].Exclusive {
+			return functions[a].Exclusive > functions[b].Exclusive
+		}
+		return functions[a].Label() < functions[b].Label()
+	})
+	return functions
+}
+
+// Returns the executed lines, the most frequently hit ones first.
+func (p *Profiler) Lines() []LineStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var lines []LineStats
+	for line, hits := range p.lines {
+		lines = append(lines, LineStats{Line: line, Hits: hits})
+	}
+
+	sort.Slice(lines, func(a, b int) bool {
+		if lines[a].Hits != lines[b].Hits {
+			return lines[a].Hits > lines[b].Hits
+		}
+		return lines[a].Line < lines[b].Line
+	})
+	return lines
+}
+
+func (p *Profiler) WriteReport(w io.Writer) error {
+	functions := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(functions, "Calls\tInclusive\tExclusive\tExclusive %%\t Function\n")
+	for _, function := range p.Functions() {
+		_, _ = fmt.Fprintf(functions, "%d\t%s\t%s\t%.1f%%\t %s\n",
+			function.Calls,
+			formatDuration(function.Inclusive),
+			formatDuration(function.Exclusive),
+			p.share(function.Exclusive),
+			function.Label(),
+		)
+	}
+	if err := functions.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(w)
+
+	lines := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(lines, "Hits\tLine\t\n")
+	hits := p.Lines()
+	if len(hits) > REPORTED_LINES {
+		hits = hits[:REPORTED_LINES]
+	}
+	for _, line := range hits {
+		_, _ = fmt.Fprintf(lines, "%d\t%d\t\n", line.Hits, line.Line)
+	}
+	return lines.Flush()
+}
+
+// Writes the exclusive time of each call stack in microseconds, in the folded format that
+// flame graph tools read.
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var stacks []string
+	for stack := range p.stacks {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+
+	for _, stack := range stacks {
+		if micros := p.stacks[stack].Microseconds(); micros > 0 {
+			if _, err := fmt.Fprintf(w, "%s %d\n", stack, micros); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Profiler) share(duration time.Duration) float64 {
+	if p.duration <= 0 {
+		return 0
+	}
+	return float64(duration) * 100 / float64(p.duration)
+}
+
+func formatDuration(duration time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(duration)/float64(time.Millisecond))
+}
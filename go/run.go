@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/lukas-reining/lox/coverage"
+	"github.com/lukas-reining/lox/lox"
+	"github.com/lukas-reining/lox/profiler"
+	"github.com/lukas-reining/lox/tracer"
+	"io"
+	"os"
+	"runtime"
+)
+
+type runOptions struct {
+	profile       bool
+	profileOut    string
+	profileFormat string
+	coverageOut   string
+	trace         bool
+	traceOut      string
+	// The functions the trace is restricted to.
+	traceFunctions []string
+}
+
+func run(loxEngine *lox.Lox, args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	parallel := flags.Bool("parallel", false, "run a batch of scripts concurrently, each on its own interpreter")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of scripts to run at the same time with --parallel")
+	cache := flags.Bool("cache", false, "reuse compiled programs from the cache directory")
+	cacheDir := flags.String("cache-dir", "", "directory for cached programs (implies --cache)")
+	optimize := flags.Bool("optimize", false, "fold constants and remove dead code before running")
+
+	var options runOptions
+	flags.BoolVar(&options.profile, "profile", false, "print a report of the time spent per function and the most executed lines")
+	flags.StringVar(&options.profileOut, "profile-out", "", "write the profile to `file`")
+	flags.StringVar(&options.profileFormat, "profile-format", "pprof", "format of --profile-out, either pprof or folded")
+	flags.StringVar(&options.coverageOut, "coverage", "", "write the statement and branch coverage as JSON to `file`")
+	flags.BoolVar(&options.trace, "trace", false, "log executed statements, calls and variable changes to stderr")
+	flags.StringVar(&options.traceOut, "trace-out", "", "write the trace to `file` instead of stderr (implies --trace)")
+	flags.Func("trace-function", "only trace calls of the `function`, can be repeated (implies --trace)", func(name string) error {
+		options.traceFunctions = append(options.traceFunctions, name)
+		return nil
+	})
+
+	err := flags.Parse(args)
+	instrumented := options.profiling() || options.coverageOut != "" || options.tracing()
+	if err != nil || flags.NArg() == 0 || *workers < 1 || (!*parallel && flags.NArg() != 1) ||
+		(*parallel && instrumented) || (options.profileFormat != "pprof" && options.profileFormat != "folded") {
+		fmt.Println("Usage: glox run [--optimize] [--cache] [--cache-dir=dir] [--profile] [--profile-out=file] [--profile-format=pprof|folded] [--coverage=file] [--trace] [--trace-out=file] [--trace-function=name] [script]")
+		fmt.Println("       glox run --parallel [--workers=n] [--optimize] [--cache] [--cache-dir=dir] [scripts...]")
+		return 64
+	}
+
+	loxEngine.SetOptimize(*optimize)
+
+	if *cache || *cacheDir != "" {
+		dir := *cacheDir
+		if dir == "" {
+			defaultDir, err := lox.DefaultCacheDir()
+			if err != nil {
+				fmt.Println(err)
+				return 1
+			}
+			dir = defaultDir
+		}
+		loxEngine.SetCache(lox.NewProgramCache(dir))
+	}
+
+	if instrumented {
+		return runInstrumented(loxEngine, flags.Arg(0), options)
+	}
+
+	if !*parallel {
+		return exitCode(loxEngine.RunFile(flags.Arg(0)))
+	}
+
+	status := 0
+	for _, result := range loxEngine.RunParallel(flags.Args(), min(*workers, flags.NArg())) {
+		fmt.Printf("==> %s <==\n", result.Path)
+		fmt.Print(result.Output)
+
+		if code := exitCode(result.Err); code > status {
+			status = code
+		}
+	}
+
+	return status
+}
+
+func (o runOptions) profiling() bool {
+	return o.profile || o.profileOut != ""
+}
+
+func (o runOptions) tracing() bool {
+	return o.trace || o.traceOut != "" || len(o.traceFunctions) > 0
+}
+
+// Runs a single script with the profiler, the coverage collector and the tracer attached as
+// requested.
+func runInstrumented(loxEngine *lox.Lox, filePath string, options runOptions) int {
+	var scriptProfiler *profiler.Profiler
+	if options.profiling() {
+		scriptProfiler = profiler.NewProfiler()
+		loxEngine.AddHook(scriptProfiler)
+	}
+
+	var collector *coverage.Collector
+	var source []byte
+	if options.coverageOut != "" {
+		// The report records the source it was collected for, read before the script runs.
+		var readErr error
+		if source, readErr = os.ReadFile(filePath); readErr != nil {
+			fmt.Printf("File not found: %s\n", filePath)
+			return 1
+		}
+
+		collector = coverage.NewCollector()
+		loxEngine.AddHook(collector)
+	}
+
+	if options.tracing() {
+		var traceOut io.Writer = os.Stderr
+		if options.traceOut != "" {
+			file, err := os.Create(options.traceOut)
+			if err != nil {
+				fmt.Println(err)
+				return 1
+			}
+			defer file.Close()
+
+			buffered := bufio.NewWriter(file)
+			defer buffered.Flush()
+			traceOut = buffered
+		}
+
+		scriptTracer := tracer.NewTracer(traceOut, filePath)
+		for _, function := range options.traceFunctions {
+			scriptTracer.Filter(function)
+		}
+		loxEngine.AddHook(scriptTracer)
+	}
+
+	if scriptProfiler != nil {
+		scriptProfiler.Start()
+	}
+	err := loxEngine.RunFile(filePath)
+	if scriptProfiler != nil {
+		scriptProfiler.Stop()
+	}
+
+	if scriptProfiler != nil {
+		if options.profile {
+			_ = scriptProfiler.WriteReport(os.Stderr)
+		}
+
+		if options.profileOut != "" {
+			if writeErr := writeProfile(scriptProfiler, filePath, options); writeErr != nil {
+				fmt.Println(writeErr)
+				return 1
+			}
+		}
+	}
+
+	if collector != nil {
+		report, reportErr := collector.Report(filePath, source)
+		if reportErr == nil {
+			reportErr = report.WriteFile(options.coverageOut)
+		}
+		if reportErr != nil {
+			fmt.Println(reportErr)
+			return 1
+		}
+	}
+
+	return exitCode(err)
+}
+
+func writeProfile(scriptProfiler *profiler.Profiler, filePath string, options runOptions) error {
+	file, err := os.Create(options.profileOut)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if options.profileFormat == "folded" {
+		return scriptProfiler.WriteFolded(file)
+	}
+	return scriptProfiler.WritePprof(file, filePath)
+}
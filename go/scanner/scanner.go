@@ -1,19 +1,70 @@
 package scanner
 
 import (
+	"sort"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+const byteOrderMark = "\uFEFF"
+
+var keywords = map[string]TokenType{
+	"and":    AND,
+	"async":  ASYNC,
+	"await":  AWAIT,
+	"case":   CASE,
+	"class":  CLASS,
+	"const":  CONST,
+	"else":   ELSE,
+	"false":  FALSE,
+	"for":    FOR,
+	"fun":    FUN,
+	"if":     IF,
+	"in":     IN,
+	"match":  MATCH,
+	"nil":    NIL,
+	"or":     OR,
+	"print":  PRINT,
+	"return": RETURN,
+	"super":  SUPER,
+	"this":   THIS,
+	"trait":  TRAIT,
+	"true":   TRUE,
+	"var":    VAR,
+	"while":  WHILE,
+	"with":   WITH,
+	"yield":  YIELD,
+}
+
+func Keywords() []string {
+	var names []string
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type Scanner struct {
 	source  string
 	start   int
 	current int
 	line    int
-	tokens  []Token
+	// The column of the current character, counting characters, not bytes.
+	column      int
+	startLine   int
+	startColumn int
+	tokens      []Token
+
+	// Open brace counts of the string interpolations we are currently inside.
+	interpolations []int
 }
 
 func NewScanner(source string) Scanner {
-	return Scanner{source: source, start: 0, current: 0, line: 1}
+	source = strings.TrimPrefix(source, byteOrderMark)
+	return Scanner{source: source, start: 0, current: 0, line: 1, column: 1}
 }
 
 func (s *Scanner) Source() (source string) {
@@ -24,42 +75,57 @@ func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source)
 }
 
-func (s *Scanner) advance() byte {
-	current := s.source[s.current]
-	s.current += 1
+func (s *Scanner) advance() rune {
+	current, size := utf8.DecodeRuneInString(s.source[s.current:])
+	s.current += size
+	s.column += 1
 	return current
 }
 
-func (s *Scanner) peek() byte {
+func (s *Scanner) peek() rune {
 	if s.isAtEnd() {
 		return 0
 	}
 
-	return s.source[s.current]
+	current, _ := utf8.DecodeRuneInString(s.source[s.current:])
+	return current
 }
 
-func (s *Scanner) peekNext() byte {
-	if s.current+1 >= len(s.source) {
+func (s *Scanner) peekNext() rune {
+	if s.isAtEnd() {
+		return 0
+	}
+
+	_, size := utf8.DecodeRuneInString(s.source[s.current:])
+	if s.current+size >= len(s.source) {
 		return 0
 	}
 
-	return s.source[s.current+1]
+	next, _ := utf8.DecodeRuneInString(s.source[s.current+size:])
+	return next
 }
 
-func (s *Scanner) match(expected byte) bool {
+// Called after consuming a line break.
+func (s *Scanner) newLine() {
+	s.line += 1
+	s.column = 1
+}
+
+func (s *Scanner) match(expected rune) bool {
 	if s.isAtEnd() {
 		return false
 	}
 
-	if s.source[s.current] != expected {
+	if s.peek() != expected {
 		return false
 	} else {
 		s.current += 1
+		s.column += 1
 		return true
 	}
 }
 
-func (s *Scanner) matchOrElse(expected byte, onMatch TokenType, onNoMatch TokenType) TokenType {
+func (s *Scanner) matchOrElse(expected rune, onMatch TokenType, onNoMatch TokenType) TokenType {
 	if s.match(expected) {
 		return onMatch
 	} else {
@@ -67,62 +133,227 @@ func (s *Scanner) matchOrElse(expected byte, onMatch TokenType, onNoMatch TokenT
 	}
 }
 
-func (s *Scanner) isDigit(character byte) bool {
+func (s *Scanner) isDigit(character rune) bool {
 	return character >= '0' && character <= '9'
 }
 
-func (s *Scanner) isAlpha(character byte) bool {
-	return (character >= 'a' && character <= 'z') ||
-		(character >= 'A' && character <= 'Z') ||
-		character == '_'
+func (s *Scanner) isAlpha(character rune) bool {
+	return unicode.IsLetter(character) || character == '_'
+}
+
+func (s *Scanner) isAlphaNumeric(character rune) bool {
+	return s.isAlpha(character) || unicode.IsDigit(character) || unicode.Is(unicode.Mn, character)
 }
 
-func (s *Scanner) isAlphaNumeric(character byte) bool {
-	return s.isAlpha(character) || s.isDigit(character)
+func (s *Scanner) handleEscape(value *strings.Builder) ScannerError {
+	if s.isAtEnd() {
+		return NewScannerError(s.line, "Unterminated string.")
+	}
+
+	switch c := s.advance(); c {
+	case 'n':
+		value.WriteByte('\n')
+	case 't':
+		value.WriteByte('\t')
+	case 'r':
+		value.WriteByte('\r')
+	case '0':
+		value.WriteByte(0)
+	case '"', '\\', '$':
+		value.WriteRune(c)
+	case 'u':
+		if !s.match('{') {
+			return NewScannerError(s.line, "Expect '{' after '\\u'.")
+		}
+
+		start := s.current
+		for s.peek() != '}' && !s.isAtEnd() {
+			s.advance()
+		}
+
+		if s.isAtEnd() {
+			return NewScannerError(s.line, "Unterminated unicode escape sequence.")
+		}
+
+		codePoint, err := strconv.ParseUint(s.source[start:s.current], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(codePoint)) {
+			return NewScannerError(s.line, "Invalid unicode escape sequence.")
+		}
+
+		// The closing "}".
+		s.advance()
+		value.WriteRune(rune(codePoint))
+	default:
+		return NewScannerError(s.line, "Invalid escape sequence '\\"+string(c)+"'.")
+	}
+
+	return nil
 }
 
 func (s *Scanner) handleString() ScannerError {
+	var value strings.Builder
+
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line += 1
+		c := s.advance()
+
+		switch {
+		case c == '\n':
+			s.newLine()
+			value.WriteRune(c)
+		case c == '\\':
+			if err := s.handleEscape(&value); err != nil {
+				return err
+			}
+		case c == '$' && s.peek() == '{':
+			// Emit the segment before the "${" and scan the embedded expression as regular tokens.
+			s.advance()
+			s.interpolations = append(s.interpolations, 0)
+			s.addToken(INTERPOLATION, value.String())
+			return nil
+		default:
+			value.WriteRune(c)
 		}
-		s.advance()
 	}
 
 	if s.isAtEnd() {
-		return NewScannerError(s.current, "Unterminated string.")
+		return NewScannerError(s.line, "Unterminated string.")
 	}
 
 	// The closing.
 	s.advance()
 
-	// Trim the surrounding quotes.
-	value := s.source[s.start+1 : s.current-1]
-	s.addToken(STRING, value)
+	s.addToken(STRING, value.String())
 
 	return nil
 }
 
+func (s *Scanner) handleLeftBrace() {
+	if depth := len(s.interpolations); depth > 0 {
+		s.interpolations[depth-1] += 1
+	}
+
+	s.addToken(LEFT_BRACE, nil)
+}
+
+func (s *Scanner) handleRightBrace() ScannerError {
+	depth := len(s.interpolations)
+	if depth == 0 {
+		s.addToken(RIGHT_BRACE, nil)
+		return nil
+	}
+
+	if s.interpolations[depth-1] > 0 {
+		s.interpolations[depth-1] -= 1
+		s.addToken(RIGHT_BRACE, nil)
+		return nil
+	}
+
+	// The "}" closes an interpolation, so the string literal continues.
+	s.interpolations = s.interpolations[:depth-1]
+	return s.handleString()
+}
+
+func (s *Scanner) isHexDigit(character rune) bool {
+	return s.isDigit(character) || (character >= 'a' && character <= 'f') || (character >= 'A' && character <= 'F')
+}
+
+func (s *Scanner) isBinaryDigit(character rune) bool {
+	return character == '0' || character == '1'
+}
+
+// Consumes digits that may be separated by single underscores.
+func (s *Scanner) digits(isDigit func(rune) bool) ScannerError {
+	for isDigit(s.peek()) || s.peek() == '_' {
+		if s.advance() == '_' && !isDigit(s.peek()) {
+			return NewScannerError(s.line, "Underscores in numbers must be between digits.")
+		}
+	}
+
+	return nil
+}
+
+func (s *Scanner) handleRadixNumber(base int, isDigit func(rune) bool) ScannerError {
+	// Consume the "x" or "b" of the prefix.
+	s.advance()
+
+	if !isDigit(s.peek()) {
+		return NewScannerError(s.line, "Expect digits after number prefix.")
+	}
+
+	if err := s.digits(isDigit); err != nil {
+		return err
+	}
+
+	text := strings.ReplaceAll(s.source[s.start+2:s.current], "_", "")
+	if number, err := strconv.ParseInt(text, base, 64); err == nil {
+		s.addToken(NUMBER, number)
+		return nil
+	} else {
+		return NewScannerError(s.line, "Integer literal out of range.")
+	}
+}
+
 func (s *Scanner) handleNumber() ScannerError {
-	for s.isDigit(s.peek()) {
-		s.advance()
+	if s.source[s.start] == '0' {
+		switch s.peek() {
+		case 'x', 'X':
+			return s.handleRadixNumber(16, s.isHexDigit)
+		case 'b', 'B':
+			return s.handleRadixNumber(2, s.isBinaryDigit)
+		}
 	}
 
+	if err := s.digits(s.isDigit); err != nil {
+		return err
+	}
+
+	isFloat := false
+
 	// Look for a fractional part.
 	if s.peek() == '.' && s.isDigit(s.peekNext()) {
+		isFloat = true
+
 		// Consume the "."
 		s.advance()
 
-		for s.isDigit(s.peek()) {
+		if err := s.digits(s.isDigit); err != nil {
+			return err
+		}
+	}
+
+	// Look for an exponent.
+	if s.peek() == 'e' || s.peek() == 'E' {
+		next := s.peekNext()
+		if s.isDigit(next) || ((next == '+' || next == '-') && s.current+2 < len(s.source) && s.isDigit(rune(s.source[s.current+2]))) {
+			isFloat = true
+
+			// Consume the "e" and the optional sign.
 			s.advance()
+			if !s.isDigit(s.peek()) {
+				s.advance()
+			}
+
+			if err := s.digits(s.isDigit); err != nil {
+				return err
+			}
+		}
+	}
+
+	text := strings.ReplaceAll(s.source[s.start:s.current], "_", "")
+	// Decimal integers too large for an int64 become floats, like all numbers did before
+	// integers existed.
+	if !isFloat {
+		if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+			s.addToken(NUMBER, number)
+			return nil
 		}
 	}
 
-	if number, err := strconv.ParseFloat(s.source[s.start:s.current], 64); err == nil {
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
 		s.addToken(NUMBER, number)
 		return nil
 	} else {
-		return NewScannerError(s.current, "Could not parse number.")
+		return NewScannerError(s.line, "Could not parse number.")
 	}
 }
 
@@ -132,41 +363,8 @@ func (s *Scanner) handleIdentifier() {
 	}
 
 	text := s.source[s.start:s.current]
-	var tokenType TokenType
-	switch text {
-	case "and":
-		tokenType = AND
-	case "class":
-		tokenType = CLASS
-	case "else":
-		tokenType = ELSE
-	case "false":
-		tokenType = FALSE
-	case "for":
-		tokenType = FOR
-	case "fun":
-		tokenType = FUN
-	case "if":
-		tokenType = IF
-	case "nil":
-		tokenType = NIL
-	case "or":
-		tokenType = OR
-	case "print":
-		tokenType = PRINT
-	case "return":
-		tokenType = RETURN
-	case "super":
-		tokenType = SUPER
-	case "this":
-		tokenType = THIS
-	case "true":
-		tokenType = TRUE
-	case "var":
-		tokenType = VAR
-	case "while":
-		tokenType = WHILE
-	default:
+	tokenType, isKeyword := keywords[text]
+	if !isKeyword {
 		tokenType = IDENTIFIER
 	}
 
@@ -175,7 +373,7 @@ func (s *Scanner) handleIdentifier() {
 
 func (s *Scanner) addToken(tokenType TokenType, literal any) {
 	text := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, NewToken(tokenType, text, literal, s.line))
+	s.tokens = append(s.tokens, NewToken(tokenType, text, literal, s.startLine, s.startColumn))
 }
 
 func (s *Scanner) scanToken() ScannerError {
@@ -188,29 +386,67 @@ func (s *Scanner) scanToken() ScannerError {
 	case ')':
 		s.addToken(RIGHT_PAREN, nil)
 	case '{':
-		s.addToken(LEFT_BRACE, nil)
+		s.handleLeftBrace()
 	case '}':
-		s.addToken(RIGHT_BRACE, nil)
+		err = s.handleRightBrace()
+	case '[':
+		s.addToken(LEFT_BRACKET, nil)
+	case ']':
+		s.addToken(RIGHT_BRACKET, nil)
 	case ',':
 		s.addToken(COMMA, nil)
 	case '.':
-		s.addToken(DOT, nil)
+		s.addToken(s.matchOrElse('.', DOT_DOT, DOT), nil)
 	case '-':
-		s.addToken(MINUS, nil)
+		s.addToken(s.matchOrElse('=', MINUS_EQUAL, MINUS), nil)
 	case '+':
-		s.addToken(PLUS, nil)
+		s.addToken(s.matchOrElse('=', PLUS_EQUAL, PLUS), nil)
 	case ';':
 		s.addToken(SEMICOLON, nil)
 	case '*':
-		s.addToken(STAR, nil)
+		if s.match('*') {
+			s.addToken(STAR_STAR, nil)
+		} else {
+			s.addToken(s.matchOrElse('=', STAR_EQUAL, STAR), nil)
+		}
+	case '%':
+		s.addToken(PERCENT, nil)
+	case '&':
+		s.addToken(AMPERSAND, nil)
+	case '|':
+		s.addToken(PIPE, nil)
+	case '^':
+		s.addToken(CARET, nil)
+	case '~':
+		s.addToken(TILDE, nil)
+	case ':':
+		s.addToken(COLON, nil)
+	case '?':
+		if s.match('?') {
+			s.addToken(QUESTION_QUESTION, nil)
+		} else {
+			s.addToken(s.matchOrElse('.', QUESTION_DOT, QUESTION), nil)
+		}
 	case '!':
 		s.addToken(s.matchOrElse('=', BANG_EQUAL, BANG), nil)
 	case '=':
-		s.addToken(s.matchOrElse('=', EQUAL_EQUAL, EQUAL), nil)
+		if s.match('>') {
+			s.addToken(ARROW, nil)
+		} else {
+			s.addToken(s.matchOrElse('=', EQUAL_EQUAL, EQUAL), nil)
+		}
 	case '<':
-		s.addToken(s.matchOrElse('=', LESS_EQUAL, LESS), nil)
+		if s.match('<') {
+			s.addToken(LESS_LESS, nil)
+		} else {
+			s.addToken(s.matchOrElse('=', LESS_EQUAL, LESS), nil)
+		}
 	case '>':
-		s.addToken(s.matchOrElse('=', GREATER_EQUAL, GREATER), nil)
+		if s.match('>') {
+			s.addToken(GREATER_GREATER, nil)
+		} else {
+			s.addToken(s.matchOrElse('=', GREATER_EQUAL, GREATER), nil)
+		}
 	case '/':
 		if s.match('/') {
 			// A comment goes until the end of the Line.
@@ -218,7 +454,7 @@ func (s *Scanner) scanToken() ScannerError {
 				s.advance()
 			}
 		} else {
-			s.addToken(SLASH, nil)
+			s.addToken(s.matchOrElse('=', SLASH_EQUAL, SLASH), nil)
 		}
 	case '"':
 		err = s.handleString()
@@ -226,23 +462,42 @@ func (s *Scanner) scanToken() ScannerError {
 	case '\t':
 	case ' ':
 	case '\n':
-		s.line += 1
+		s.newLine()
 	default:
 		if s.isDigit(c) {
 			err = s.handleNumber()
 		} else if s.isAlpha(c) {
 			s.handleIdentifier()
 		} else {
-			err = NewScannerError(s.current, "Unexpected character.")
+			err = NewScannerError(s.line, "Unexpected character.")
 		}
 	}
 
 	return err
 }
 
+func (s *Scanner) validateEncoding() ScannerError {
+	for offset, character := range s.source {
+		if character == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s.source[offset:]); size == 1 {
+				line := strings.Count(s.source[:offset], "\n") + 1
+				return NewScannerError(line, "Invalid UTF-8 encoding.")
+			}
+		}
+	}
+
+	return nil
+}
+
 func (s *Scanner) ScanTokens() ([]Token, ScannerError) {
+	if err := s.validateEncoding(); err != nil {
+		return nil, err
+	}
+
 	for !s.isAtEnd() {
 		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.column
 		err := s.scanToken()
 
 		if err != nil {
@@ -250,7 +505,11 @@ func (s *Scanner) ScanTokens() ([]Token, ScannerError) {
 		}
 	}
 
-	s.tokens = append(s.tokens, NewToken(EOF, "", nil, s.line))
+	if len(s.interpolations) > 0 {
+		return nil, NewScannerError(s.line, "Unterminated string interpolation.")
+	}
+
+	s.tokens = append(s.tokens, NewToken(EOF, "", nil, s.line, s.column))
 
 	return s.tokens, nil
 }
@@ -4,41 +4,68 @@ type TokenType string
 
 const (
 	// Single-character tokens
-	LEFT_PAREN  TokenType = "LEFT_PAREN"
-	RIGHT_PAREN           = "RIGHT_PAREN"
-	LEFT_BRACE            = "LEFT_BRACE"
-	RIGHT_BRACE           = "RIGHT_BRACE"
-	COMMA                 = "COMMA"
-	DOT                   = "DOT"
-	MINUS                 = "MINUS"
-	PLUS                  = "PLUS"
-	SEMICOLON             = "SEMICOLON"
-	SLASH                 = "SLASH"
-	STAR                  = "STAR"
+	LEFT_PAREN    TokenType = "LEFT_PAREN"
+	RIGHT_PAREN             = "RIGHT_PAREN"
+	LEFT_BRACE              = "LEFT_BRACE"
+	RIGHT_BRACE             = "RIGHT_BRACE"
+	LEFT_BRACKET            = "LEFT_BRACKET"
+	RIGHT_BRACKET           = "RIGHT_BRACKET"
+	COMMA                   = "COMMA"
+	DOT                     = "DOT"
+	DOT_DOT                 = "DOT_DOT"
+	MINUS                   = "MINUS"
+	PLUS                    = "PLUS"
+	SEMICOLON               = "SEMICOLON"
+	SLASH                   = "SLASH"
+	STAR                    = "STAR"
+	PERCENT                 = "PERCENT"
+	AMPERSAND               = "AMPERSAND"
+	PIPE                    = "PIPE"
+	CARET                   = "CARET"
+	TILDE                   = "TILDE"
+	QUESTION                = "QUESTION"
+	COLON                   = "COLON"
 
 	// One or two character tokens
-	BANG          = "BANG"
-	BANG_EQUAL    = "BANG_EQUAL"
-	EQUAL         = "EQUAL"
-	EQUAL_EQUAL   = "EQUAL_EQUAL"
-	GREATER       = "GREATER"
-	GREATER_EQUAL = "GREATER_EQUAL"
-	LESS          = "LESS"
-	LESS_EQUAL    = "LESS_EQUAL"
+	BANG              = "BANG"
+	BANG_EQUAL        = "BANG_EQUAL"
+	EQUAL             = "EQUAL"
+	EQUAL_EQUAL       = "EQUAL_EQUAL"
+	GREATER           = "GREATER"
+	GREATER_EQUAL     = "GREATER_EQUAL"
+	LESS              = "LESS"
+	LESS_EQUAL        = "LESS_EQUAL"
+	LESS_LESS         = "LESS_LESS"
+	GREATER_GREATER   = "GREATER_GREATER"
+	STAR_STAR         = "STAR_STAR"
+	PLUS_EQUAL        = "PLUS_EQUAL"
+	MINUS_EQUAL       = "MINUS_EQUAL"
+	STAR_EQUAL        = "STAR_EQUAL"
+	SLASH_EQUAL       = "SLASH_EQUAL"
+	QUESTION_QUESTION = "QUESTION_QUESTION"
+	QUESTION_DOT      = "QUESTION_DOT"
+	ARROW             = "ARROW"
 
 	// Literals
-	IDENTIFIER = "IDENTIFIER"
-	STRING     = "STRING"
-	NUMBER     = "NUMBER"
+	IDENTIFIER    = "IDENTIFIER"
+	STRING        = "STRING"
+	INTERPOLATION = "INTERPOLATION"
+	NUMBER        = "NUMBER"
 
 	// Keywords
 	AND   = "AND"
+	ASYNC = "ASYNC"
+	AWAIT = "AWAIT"
+	CASE  = "CASE"
 	CLASS = "CLASS"
+	CONST = "CONST"
 	ELSE  = "ELSE"
 	FALSE = "FALSE"
 	FUN   = "FUN"
 	FOR   = "FOR"
 	IF    = "IF"
+	IN    = "IN"
+	MATCH = "MATCH"
 	NIL   = "NIL"
 	OR    = "OR"
 
@@ -46,25 +73,30 @@ const (
 	RETURN = "RETURN"
 	SUPER  = "SUPER"
 	THIS   = "THIS"
+	TRAIT  = "TRAIT"
 	TRUE   = "TRUE"
 	VAR    = "VAR"
 	WHILE  = "WHILE"
+	WITH   = "WITH"
+	YIELD  = "YIELD"
 	EOF    = "EOF"
 )
 
 type Token struct {
-	Type    TokenType
-	Lexeme  string
-	Literal any
-	Line    int
+	Type    TokenType `json:"type"`
+	Lexeme  string    `json:"lexeme"`
+	Literal any       `json:"literal"`
+	Line    int       `json:"line"`
+	Column  int       `json:"column"`
 }
 
-func NewToken(tokenType TokenType, lexeme string, literal any, line int) Token {
+func NewToken(tokenType TokenType, lexeme string, literal any, line int, column int) Token {
 	return Token{
 		Type:    tokenType,
 		Lexeme:  lexeme,
 		Literal: literal,
 		Line:    line,
+		Column:  column,
 	}
 }
 
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lukas-reining/lox/lox"
+	"os"
+)
+
+func test(loxEngine *lox.Lox, args []string) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	junitOut := flags.String("junit", "", "also write the results as JUnit XML to `file`")
+	optimize := flags.Bool("optimize", false, "fold constants and remove dead code before running")
+
+	if err := flags.Parse(args); err != nil {
+		fmt.Println("Usage: glox test [--optimize] [--junit=file] [files or directories...]")
+		return 64
+	}
+
+	loxEngine.SetOptimize(*optimize)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := lox.FindTestFiles(paths)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	var results []lox.TestResult
+	passed, failed := 0, 0
+	for _, file := range files {
+		for _, result := range loxEngine.RunTestFile(file) {
+			fmt.Print(result)
+			if result.Status == lox.TEST_PASSED {
+				passed++
+			} else {
+				failed++
+			}
+			results = append(results, result)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed in %d files\n", passed, failed, len(files))
+
+	if *junitOut != "" {
+		file, err := os.Create(*junitOut)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer file.Close()
+
+		if err := lox.WriteJUnit(file, results); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
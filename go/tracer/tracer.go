@@ -0,0 +1,178 @@
+package tracer
+
+import (
+	"fmt"
+	"github.com/lukas-reining/lox/interpeter"
+	"github.com/lukas-reining/lox/parser/statements"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The Tracer is a hook that logs every executed statement, every function call with its
+// arguments and result, and every variable that is defined or assigned. It writes to its own
+// writer, so the trace doesn't interleave with what the script prints.
+type Tracer struct {
+	interpeter.BaseHook
+
+	mutex sync.Mutex
+	out   io.Writer
+	file  string
+	// When not empty, only events inside calls of these functions are logged.
+	functions map[string]bool
+	// The line of the statement each frame of an interpreter executes, by the depth of the frame.
+	lines map[*interpeter.Interpreter][]int
+	// Set while a value is formatted. A user defined toString runs then, which isn't traced.
+	formatting atomic.Bool
+}
+
+func NewTracer(out io.Writer, file string) *Tracer {
+	return &Tracer{out: out, file: file, functions: map[string]bool{}, lines: map[*interpeter.Interpreter][]int{}}
+}
+
+// Restricts the trace to calls of the function, including everything that runs while it is on
+// the call stack. Can be called multiple times to trace several functions.
+func (t *Tracer) Filter(function string) {
+	t.functions[function] = true
+}
+
+func (t *Tracer) BeforeStatement(interpreter *interpeter.Interpreter, statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) interpeter.RuntimeError {
+	if t.formatting.Load() {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.setLine(interpreter, statement.Line())
+	t.log(interpreter, statement.Line(), statementKind(statement))
+	return nil
+}
+
+func (t *Tracer) EnterFunction(interpreter *interpeter.Interpreter, frame *interpeter.CallFrame) {
+	if t.formatting.Load() {
+		return
+	}
+
+	args := make([]string, 0, len(frame.Arguments))
+	for _, arg := range frame.Arguments {
+		args = append(args, t.format(interpreter, arg))
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Until its first statement, a call is at the function's declaration.
+	t.setLine(interpreter, frame.Line)
+	t.log(interpreter, frame.Line, fmt.Sprintf("enter %s(%s)", frame.Name, strings.Join(args, ", ")))
+}
+
+func (t *Tracer) ExitFunction(interpreter *interpeter.Interpreter, frame *interpeter.CallFrame, value interpeter.LoxValue, err interpeter.RuntimeError) {
+	if t.formatting.Load() {
+		return
+	}
+
+	var event string
+	if err != nil {
+		event = fmt.Sprintf("exit %s with error: %s", frame.Name, err.Message())
+	} else {
+		event = fmt.Sprintf("exit %s -> %s", frame.Name, t.format(interpreter, value))
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.log(interpreter, t.line(interpreter), event)
+}
+
+func (t *Tracer) DefineVariable(interpreter *interpeter.Interpreter, name string, value interpeter.LoxValue) {
+	t.variable(interpreter, "define", name, value)
+}
+
+func (t *Tracer) AssignVariable(interpreter *interpeter.Interpreter, name string, value interpeter.LoxValue) {
+	t.variable(interpreter, "assign", name, value)
+}
+
+func (t *Tracer) variable(interpreter *interpeter.Interpreter, change string, name string, value interpeter.LoxValue) {
+	if t.formatting.Load() {
+		return
+	}
+
+	event := fmt.Sprintf("%s %s = %s", change, name, t.format(interpreter, value))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.log(interpreter, t.line(interpreter), event)
+}
+
+func (t *Tracer) setLine(interpreter *interpeter.Interpreter, line int) {
+	depth := len(interpreter.Frames())
+	lines := t.lines[interpreter]
+	for len(lines) < depth {
+		lines = append(lines, 0)
+	}
+	lines[depth-1] = line
+	t.lines[interpreter] = lines[:depth]
+}
+
+// Returns the line the innermost frame of the interpreter is executing.
+func (t *Tracer) line(interpreter *interpeter.Interpreter) int {
+	depth := len(interpreter.Frames())
+	if lines := t.lines[interpreter]; depth <= len(lines) {
+		return lines[depth-1]
+	}
+	return 0
+}
+
+func (t *Tracer) log(interpreter *interpeter.Interpreter, line int, event string) {
+	frames := interpreter.Frames()
+	if !t.traced(frames) {
+		return
+	}
+
+	// Events are indented by the depth of the call stack.
+	indent := strings.Repeat("  ", len(frames)-1)
+	_, _ = fmt.Fprintf(t.out, "%s:%d: %s%s\n", t.file, line, indent, event)
+}
+
+func (t *Tracer) traced(frames []*interpeter.CallFrame) bool {
+	if len(t.functions) == 0 {
+		return true
+	}
+
+	for _, frame := range frames {
+		if t.functions[frame.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the name of the statement's node type, like Var or If.
+func statementKind(statement statements.Statement[interpeter.LoxValue, interpeter.RuntimeError]) string {
+	kind := reflect.TypeOf(statement).Elem().Name()
+	if index := strings.Index(kind, "["); index >= 0 {
+		kind = kind[:index]
+	}
+	return kind
+}
+
+// Formats a value the way print shows it, with strings quoted. Must not be called while
+// holding the mutex, the hooks of a user defined toString would wait for it.
+func (t *Tracer) format(interpreter *interpeter.Interpreter, value interpeter.LoxValue) string {
+	if text, ok := value.(string); ok {
+		return fmt.Sprintf("%q", text)
+	}
+
+	t.formatting.Store(true)
+	defer t.formatting.Store(false)
+
+	// An instance whose toString fails, for example because it isn't initialized yet, is shown
+	// without it.
+	text, err := interpreter.Stringify(value)
+	if err != nil {
+		return interpeter.Stringify(value)
+	}
+	return text
+}